@@ -0,0 +1,402 @@
+package xlsx_reader
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+//单元格值的类型
+type CellKind int
+
+const (
+	KindEmpty  = CellKind(0) //空单元格
+	KindString = CellKind(1) //共享字符串、内联字符串及公式字符串结果
+	KindNumber = CellKind(2)
+	KindBool   = CellKind(3)
+	KindError  = CellKind(4) //#N/A、#DIV/0! 等错误值
+	KindDate   = CellKind(5)
+)
+
+func (this CellKind) String() string {
+	switch this {
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	case KindError:
+		return "error"
+	case KindDate:
+		return "date"
+	}
+	return "empty"
+}
+
+//带类型的单元格
+type Cell struct {
+	Row   int //从1开始的行号
+	Col   int //从0开始的列序号，工作表中不存在的列为-1
+	Kind  CellKind
+	Raw   string      //字符串类型为字符串内容，其他类型为<v>中的原始文本
+	Value interface{} //解析后的值：string、float64、bool、time.Time，空单元格为nil
+
+	style    int  //s 属性，cellXfs 中的样式序号
+	date1904 bool //所在工作簿是否使用1904 日期系统
+}
+
+//单元格的文本，同FetchRow 中的值
+func (this Cell) String() string {
+	return this.Raw
+}
+
+//数值类型及日期类型(Excel 序列值)返回对应的float64
+func (this Cell) Float() (float64, bool) {
+	switch this.Kind {
+	case KindNumber:
+		v, ok := this.Value.(float64)
+		return v, ok
+	case KindDate:
+		v, err := strconv.ParseFloat(this.Raw, 64)
+		return v, err == nil
+	}
+	return 0, false
+}
+
+func (this Cell) Bool() (bool, bool) {
+	v, ok := this.Value.(bool)
+	return v, ok
+}
+
+func (this Cell) Time() (time.Time, bool) {
+	v, ok := this.Value.(time.Time)
+	return v, ok
+}
+
+//根据Kind 与Raw 解析Value，无法解析的数值作为字符串
+//date1904:日期是否从1904-01-01 开始计算
+func (this *Cell) parse(date1904 bool) {
+	this.date1904 = date1904
+	switch this.Kind {
+	case KindString, KindError:
+		this.Value = this.Raw
+	case KindNumber:
+		if v, err := strconv.ParseFloat(this.Raw, 64); err == nil {
+			this.Value = v
+		} else {
+			this.Kind = KindString
+			this.Value = this.Raw
+		}
+	case KindBool:
+		this.Value = this.Raw == "1" || strings.EqualFold(this.Raw, "true")
+	case KindDate:
+		//日期格式的数值为Excel 序列值，t="d" 的单元格为ISO 8601 格式
+		if v, err := strconv.ParseFloat(this.Raw, 64); err == nil {
+			this.Value = GetExcelTime(v, date1904)
+		} else if v, err := parseISODate(this.Raw); err == nil {
+			this.Value = v
+		} else {
+			this.Kind = KindString
+			this.Value = this.Raw
+		}
+	default:
+		this.Value = nil
+	}
+}
+
+var isoLayouts = []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+
+func parseISODate(s string) (t time.Time, err error) {
+	for _, layout := range isoLayouts {
+		if t, err = time.Parse(layout, s); err == nil {
+			return
+		}
+	}
+	return
+}
+
+//单元格t 属性对应的类型
+func cellKind(t string) CellKind {
+	switch t {
+	case "s", "inlineStr", "str":
+		return KindString
+	case "b":
+		return KindBool
+	case "e":
+		return KindError
+	case "d":
+		return KindDate
+	}
+	return KindNumber
+}
+
+//读取下一个<row>，其中有值的单元格按列顺序写入this.rowCells
+//返回false 表示sheetData 已读取完毕
+func (this *reader) readRow() (bool, error) {
+	var cell Cell
+	var t string
+	var text []byte
+	var inRow, inCell, inValue, inText, inPhonetic, hasValue bool
+	for {
+		tok, err := this.sheetXmlDecoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, err
+		}
+		switch token := tok.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "row":
+				inRow = true
+				this.rowNum++
+				this.rowCells = this.rowCells[:0]
+				this.prevCol = -1
+				for _, v := range token.Attr {
+					if v.Name.Local == "r" {
+						if n, err := strconv.Atoi(v.Value); err == nil {
+							this.rowNum = n
+						}
+					}
+				}
+				if this.rng != nil {
+					//区域之后的行不再读取，之前的行直接跳过
+					if this.rowNum > this.rng.End.Row {
+						return false, nil
+					}
+					if this.rowNum < this.rng.Start.Row {
+						inRow = false
+						if err := this.sheetXmlDecoder.Skip(); err != nil {
+							return false, err
+						}
+					}
+				}
+			case "c":
+				if !inRow {
+					break
+				}
+				inCell, hasValue, t = true, false, ""
+				text = text[:0]
+				cell = Cell{Row: this.rowNum, Col: this.prevCol + 1}
+				for _, v := range token.Attr {
+					switch v.Name.Local {
+					case "t":
+						t = v.Value
+					case "r":
+						if ref, err := cellref.ParseA1(v.Value); err == nil {
+							cell.Col = ref.Col
+						}
+					case "s":
+						cell.style, _ = strconv.Atoi(v.Value)
+					}
+				}
+				//区域之外的列跳过整个<c>
+				if this.rng != nil && (cell.Col < this.rng.Start.Col || cell.Col > this.rng.End.Col) {
+					inCell = false
+					this.prevCol = cell.Col
+					if err := this.sheetXmlDecoder.Skip(); err != nil {
+						return false, err
+					}
+				}
+			case "v":
+				inValue = inCell
+			case "t":
+				//内联字符串<is><t>，忽略<rPh>中的注音
+				inText = inCell && t == "inlineStr" && !inPhonetic
+			case "rPh":
+				inPhonetic = true
+			case "dimension":
+				for _, v := range token.Attr {
+					if v.Name.Local == "ref" {
+						this.dimension = v.Value
+					}
+				}
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "row":
+				return true, nil
+			case "c":
+				if inCell {
+					inCell = false
+					this.prevCol = cell.Col
+					if hasValue {
+						cell.Kind = cellKind(t)
+						cell.Raw = string(text)
+						if cell.Kind == KindNumber && this.styles.isDate(cell.style) {
+							cell.Kind = KindDate
+						}
+						if t == "s" {
+							i, _ := strconv.Atoi(cell.Raw)
+							cell.Raw = this.getString(i)
+						}
+						this.rowCells = append(this.rowCells, cell)
+					}
+				}
+			case "v":
+				inValue = false
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			case "sheetData":
+				return false, nil
+			}
+		case xml.CharData:
+			if inValue || inText {
+				text = append(text, token...)
+				hasValue = true
+			}
+		}
+	}
+}
+
+//共享字符串，超出范围时返回空字符串
+func (this *reader) getString(i int) string {
+	switch this.policy {
+	case Fast:
+		if i < 0 || i >= len(this.stringCache) {
+			return ""
+		}
+		return this.stringCache[i]
+	case Indexed:
+		if this.stringIndex == nil {
+			return ""
+		}
+		return this.stringIndex.get(i)
+	}
+	return this.findString(i)
+}
+
+//当前行有值的单元格，补充的空行没有单元格
+func (this *reader) currentCells() []Cell {
+	if this.blankRow {
+		return nil
+	}
+	return this.rowCells
+}
+
+//将当前行转换为FetchRow 中的[]string
+//firstRowIsCol 为true 时按列映射输出，否则按列序号输出
+func (this *reader) stringRow() []string {
+	if this.firstRowIsCol {
+		row := make([]string, len(this.cols))
+		for _, c := range this.currentCells() {
+			//忽略超过指定列的数据
+			if c.Col > this.maxIndex {
+				break
+			}
+			if i, ok := this.columnMaps[c.Col]; ok {
+				row[i] = this.cellText(c)
+			}
+		}
+		return row
+	}
+	row := this.denseRow()
+	if w := this.rowWidth(len(row)); w > 0 {
+		if len(row) > w {
+			row = row[:w]
+		}
+		for len(row) < w {
+			row = append(row, "")
+		}
+	}
+	return row
+}
+
+//按列序号输出当前行，中间缺少的列补空字符串
+func (this *reader) denseRow() []string {
+	row := []string{}
+	off := this.colOffset()
+	for _, c := range this.currentCells() {
+		for len(row) < c.Col-off {
+			row = append(row, "")
+		}
+		row = append(row, this.cellText(c))
+	}
+	return row
+}
+
+//FetchRow 中单元格的文本
+func (this *reader) cellText(c Cell) string {
+	if this.formatted {
+		return this.FormatCell(c)
+	}
+	return c.Raw
+}
+
+//将当前行转换为FetchCells 中的[]Cell，对齐方式同stringRow
+func (this *reader) cellRow() []Cell {
+	var row []Cell
+	if this.firstRowIsCol {
+		row = make([]Cell, len(this.cols))
+		for i := range row {
+			row[i] = Cell{Row: this.curRow, Col: -1}
+		}
+		for j, i := range this.columnMaps {
+			row[i].Col = j
+		}
+		for _, c := range this.currentCells() {
+			if c.Col > this.maxIndex {
+				break
+			}
+			if i, ok := this.columnMaps[c.Col]; ok {
+				row[i] = c
+				row[i].parse(this.workbook.date1904)
+			}
+		}
+		return row
+	}
+	row = []Cell{}
+	w := this.rowWidth(-1)
+	off := this.colOffset()
+	for _, c := range this.currentCells() {
+		if w > 0 && c.Col-off >= w {
+			break
+		}
+		for len(row) < c.Col-off {
+			row = append(row, Cell{Row: this.curRow, Col: off + len(row)})
+		}
+		c.parse(this.workbook.date1904)
+		row = append(row, c)
+	}
+	for len(row) < w {
+		row = append(row, Cell{Row: this.curRow, Col: off + len(row)})
+	}
+	return row
+}
+
+//firstRowIsCol 为false 时输出的行宽度，0 为不固定
+//n:当前行按列序号输出时的长度，WidthHeader 时用首行的长度作为宽度
+func (this *reader) rowWidth(n int) int {
+	switch this.width {
+	case WidthDimension:
+		rng, err := cellref.ParseRange(this.dimension)
+		if err != nil {
+			return 0
+		}
+		end := rng.End.Col
+		if this.rng != nil && this.rng.End.Col < end {
+			end = this.rng.End.Col
+		}
+		this.width = end - this.colOffset() + 1
+		if this.width < 0 {
+			this.width = 0
+		}
+	case WidthHeader:
+		if n < 0 {
+			n = 0
+			if cells := this.currentCells(); len(cells) > 0 {
+				n = cells[len(cells)-1].Col - this.colOffset() + 1
+			}
+		}
+		this.width = n
+	}
+	return this.width
+}
@@ -0,0 +1,83 @@
+package xlsx_reader
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var typedFixture = fixture{
+	sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1">` +
+		`<c r="A1" t="s"><v>0</v></c>` +
+		`<c r="B1"><v>1.5</v></c>` +
+		`<c r="C1" t="b"><v>1</v></c>` +
+		`<c r="D1" t="e"><f>1/0</f><v>#DIV/0!</v></c>` +
+		`<c r="E1" t="inlineStr"><is><r><t>内联</t></r><r><t xml:space="preserve"> 字符串</t></r><rPh sb="0" eb="1"><t>ナイ</t></rPh></is></c>` +
+		`<c r="F1" t="str"><f>A1&amp;"!"</f><v>富文本!</v></c>` +
+		`<c r="H1" s="1"/>` +
+		`<c r="I1" t="d"><v>2024-03-05T08:30:00</v></c>` +
+		`<c><v>7</v></c>` +
+		`</row>`}},
+	files: map[string]string{"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="1" uniqueCount="1">` +
+		`<si><r><t>富</t></r>` + "\n" + `<r><rPr><b/></rPr><t>文本</t></r></si></sst>`},
+}
+
+func TestReader_FetchCells(t *testing.T) {
+	r := ReaderFromBytes(typedFixture.bytes(t), "", false)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var cells []Cell
+	err := r.FetchCells(func(row []Cell) error {
+		cells = row
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Cell{
+		{Row: 1, Col: 0, Kind: KindString, Raw: "富文本", Value: "富文本"},
+		{Row: 1, Col: 1, Kind: KindNumber, Raw: "1.5", Value: 1.5},
+		{Row: 1, Col: 2, Kind: KindBool, Raw: "1", Value: true},
+		{Row: 1, Col: 3, Kind: KindError, Raw: "#DIV/0!", Value: "#DIV/0!"},
+		{Row: 1, Col: 4, Kind: KindString, Raw: "内联 字符串", Value: "内联 字符串"},
+		{Row: 1, Col: 5, Kind: KindString, Raw: "富文本!", Value: "富文本!"},
+		{Row: 1, Col: 6},
+		{Row: 1, Col: 7},
+		{Row: 1, Col: 8, Kind: KindDate, Raw: "2024-03-05T08:30:00", Value: time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{Row: 1, Col: 9, Kind: KindNumber, Raw: "7", Value: float64(7)},
+	}
+	if !reflect.DeepEqual(cells, want) {
+		t.Errorf("cells=\n%v\nwant\n%v", cells, want)
+	}
+}
+
+func TestReader_FetchRowGaps(t *testing.T) {
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1"><v>1</v></c><c r="B1"><v>2</v></c><c r="D1"><v>4</v></c></row>`}}}
+	r := ReaderFromBytes(f.bytes(t), "", false)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"1", "2", "", "4"}}) {
+		t.Errorf("rows=%v", rows)
+	}
+}
+
+func TestReader_FetchCellsWithCols(t *testing.T) {
+	r := ReaderFromBytes(simpleFixture.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidCols([]string{"年龄", "姓名"}); err != nil {
+		t.Fatal(err)
+	}
+	err := r.FetchCells(func(cells []Cell) error {
+		if len(cells) != 2 || cells[0].Value != float64(18) || cells[1].Value != "张三" || cells[0].Col != 1 {
+			t.Errorf("cells=%v", cells)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,358 @@
+//单元格引用的解析与格式化：A1、R1C1、区域及列名与列序号的转换
+//列序号从0开始，行号从1开始，与Excel 中显示的行号一致
+package cellref
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxColumns = 16384   //最大列数，最后一列为XFD
+	MaxRows    = 1048576 //最大行数
+)
+
+var (
+	ErrRef    = errors.New("Invalid cell reference")
+	ErrColumn = errors.New("Invalid column name")
+	ErrRange  = errors.New("Invalid range")
+)
+
+//单元格引用
+type Ref struct {
+	Col    int  //从0开始的列序号
+	Row    int  //从1开始的行号
+	ColAbs bool //列为绝对引用，如$B7
+	RowAbs bool //行为绝对引用，如B$7
+}
+
+//列名转换为列序号，A 为0，不区分大小写
+func ColumnIndex(name string) (int, error) {
+	if name == "" || len(name) > 3 {
+		return 0, ErrColumn
+	}
+	index := 0
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c < 'A' || c > 'Z' {
+			return 0, ErrColumn
+		}
+		index = index*26 + int(c-'A') + 1
+	}
+	if index > MaxColumns {
+		return 0, ErrColumn
+	}
+	return index - 1, nil
+}
+
+//列序号转换为列名，0 为A
+func ColumnName(index int) string {
+	var buf [8]byte
+	return string(AppendColumnName(buf[:0], index))
+}
+
+//将列名追加到dst，不分配内存
+func AppendColumnName(dst []byte, index int) []byte {
+	var buf [8]byte
+	i := len(buf)
+	for index++; index > 0 && i > 0; index = (index - 1) / 26 {
+		i--
+		buf[i] = byte('A' + (index-1)%26)
+	}
+	return append(dst, buf[i:]...)
+}
+
+//解析A1 格式的引用，如B7、$B$7、aa10
+func ParseA1(s string) (Ref, error) {
+	var ref Ref
+	i := 0
+	if i < len(s) && s[i] == '$' {
+		ref.ColAbs = true
+		i++
+	}
+	start := i
+	for i < len(s) && isLetter(s[i]) {
+		i++
+	}
+	col, err := ColumnIndex(s[start:i])
+	if err != nil {
+		return ref, ErrRef
+	}
+	ref.Col = col
+	if i < len(s) && s[i] == '$' {
+		ref.RowAbs = true
+		i++
+	}
+	row, ok := parseRow(s[i:])
+	if !ok {
+		return ref, ErrRef
+	}
+	ref.Row = row
+	return ref, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+//解析1 到MaxRows 之间的行号
+func parseRow(s string) (int, bool) {
+	if s == "" || len(s) > 7 || s[0] == '0' {
+		return 0, false
+	}
+	row := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		row = row*10 + int(s[i]-'0')
+	}
+	return row, row <= MaxRows
+}
+
+//A1 格式，绝对引用带$
+func (this Ref) String() string {
+	var buf [16]byte
+	return string(this.AppendA1(buf[:0]))
+}
+
+//将A1 格式的引用追加到dst
+func (this Ref) AppendA1(dst []byte) []byte {
+	if this.ColAbs {
+		dst = append(dst, '$')
+	}
+	dst = AppendColumnName(dst, this.Col)
+	if this.RowAbs {
+		dst = append(dst, '$')
+	}
+	return strconv.AppendInt(dst, int64(this.Row), 10)
+}
+
+//解析R1C1 格式的引用，如R7C2、R[-1]C[2]、RC，方括号中的相对位置以base 为基准
+//R 或C 后面是数字时为绝对引用
+func ParseR1C1(s string, base Ref) (Ref, error) {
+	var ref Ref
+	if len(s) < 2 || (s[0] != 'R' && s[0] != 'r') {
+		return ref, ErrRef
+	}
+	c := strings.IndexAny(s, "Cc")
+	if c < 0 {
+		return ref, ErrRef
+	}
+	row, rowAbs, ok := parseR1C1Part(s[1:c], base.Row)
+	if !ok || row < 1 || row > MaxRows {
+		return ref, ErrRef
+	}
+	col, colAbs, ok := parseR1C1Part(s[c+1:], base.Col+1)
+	if !ok || col < 1 || col > MaxColumns {
+		return ref, ErrRef
+	}
+	return Ref{Col: col - 1, Row: row, ColAbs: colAbs, RowAbs: rowAbs}, nil
+}
+
+//R1C1 中R 或C 后面的部分：空、数字或[偏移]，返回从1开始的位置
+func parseR1C1Part(s string, base int) (int, bool, bool) {
+	switch {
+	case s == "":
+		return base, false, true
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return 0, false, false
+		}
+		offset, err := strconv.Atoi(s[1 : len(s)-1])
+		return base + offset, false, err == nil
+	}
+	n, err := strconv.Atoi(s)
+	return n, true, err == nil && s[0] != '+' && s[0] != '-'
+}
+
+//R1C1 格式，绝对引用为R7C2 形式，相对引用为相对于base 的R[-1]C[2] 形式
+func (this Ref) R1C1(base Ref) string {
+	buf := make([]byte, 0, 16)
+	buf = append(buf, 'R')
+	buf = appendR1C1Part(buf, this.Row, base.Row, this.RowAbs)
+	buf = append(buf, 'C')
+	buf = appendR1C1Part(buf, this.Col+1, base.Col+1, this.ColAbs)
+	return string(buf)
+}
+
+func appendR1C1Part(dst []byte, n, base int, abs bool) []byte {
+	if abs {
+		return strconv.AppendInt(dst, int64(n), 10)
+	}
+	if n == base {
+		return dst
+	}
+	dst = append(dst, '[')
+	dst = strconv.AppendInt(dst, int64(n-base), 10)
+	return append(dst, ']')
+}
+
+//矩形区域，包含Start 与End
+type Range struct {
+	Start Ref //左上角
+	End   Ref //右下角
+}
+
+//解析区域，如B2:F100、整列C:E、整行3:9，单个单元格B2 也是区域
+//起止顺序颠倒时自动调整为左上角与右下角
+func ParseRange(s string) (Range, error) {
+	var rng Range
+	first, second := s, s
+	if i := strings.IndexByte(s, ':'); i > -1 {
+		first, second = s[:i], s[i+1:]
+	}
+	start, err := parseRangePart(first, true)
+	if err != nil {
+		return rng, err
+	}
+	end, err := parseRangePart(second, false)
+	if err != nil {
+		return rng, err
+	}
+	//单元格、整列与整行不能混用，如A:3
+	if isWholeCol(first) != isWholeCol(second) || isWholeRow(first) != isWholeRow(second) {
+		return rng, ErrRange
+	}
+	if start.Col > end.Col {
+		start.Col, end.Col = end.Col, start.Col
+		start.ColAbs, end.ColAbs = end.ColAbs, start.ColAbs
+	}
+	if start.Row > end.Row {
+		start.Row, end.Row = end.Row, start.Row
+		start.RowAbs, end.RowAbs = end.RowAbs, start.RowAbs
+	}
+	return Range{Start: start, End: end}, nil
+}
+
+func isWholeCol(s string) bool {
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWholeRow(s string) bool {
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+//区域的一端，整列时行取1或MaxRows，整行时列取A或XFD
+func parseRangePart(s string, start bool) (Ref, error) {
+	switch {
+	case isWholeCol(s):
+		col, err := ColumnIndex(strings.TrimPrefix(s, "$"))
+		if err != nil {
+			return Ref{}, ErrRange
+		}
+		ref := Ref{Col: col, ColAbs: s[0] == '$', Row: MaxRows}
+		if start {
+			ref.Row = 1
+		}
+		return ref, nil
+	case isWholeRow(s):
+		row, ok := parseRow(strings.TrimPrefix(s, "$"))
+		if !ok {
+			return Ref{}, ErrRange
+		}
+		ref := Ref{Row: row, RowAbs: s[0] == '$', Col: MaxColumns - 1}
+		if start {
+			ref.Col = 0
+		}
+		return ref, nil
+	}
+	ref, err := ParseA1(s)
+	if err != nil {
+		return ref, ErrRange
+	}
+	return ref, nil
+}
+
+//区域是否包含单元格，col 从0开始，row 从1开始
+func (this Range) Contains(col, row int) bool {
+	return col >= this.Start.Col && col <= this.End.Col && row >= this.Start.Row && row <= this.End.Row
+}
+
+//列数
+func (this Range) Cols() int {
+	return this.End.Col - this.Start.Col + 1
+}
+
+//行数
+func (this Range) Rows() int {
+	return this.End.Row - this.Start.Row + 1
+}
+
+//是否为整列，如C:E
+func (this Range) WholeColumns() bool {
+	return this.Start.Row == 1 && this.End.Row == MaxRows
+}
+
+//是否为整行，如3:9
+func (this Range) WholeRows() bool {
+	return this.Start.Col == 0 && this.End.Col == MaxColumns-1
+}
+
+//格式化为B2:F100、C:E 或3:9，单个单元格为B2
+func (this Range) String() string {
+	buf := make([]byte, 0, 24)
+	switch {
+	case this.WholeColumns() && !this.WholeRows():
+		buf = appendAbs(buf, this.Start.ColAbs)
+		buf = AppendColumnName(buf, this.Start.Col)
+		buf = append(buf, ':')
+		buf = appendAbs(buf, this.End.ColAbs)
+		buf = AppendColumnName(buf, this.End.Col)
+	case this.WholeRows():
+		buf = appendAbs(buf, this.Start.RowAbs)
+		buf = strconv.AppendInt(buf, int64(this.Start.Row), 10)
+		buf = append(buf, ':')
+		buf = appendAbs(buf, this.End.RowAbs)
+		buf = strconv.AppendInt(buf, int64(this.End.Row), 10)
+	default:
+		buf = this.Start.AppendA1(buf)
+		if this.End != this.Start {
+			buf = append(buf, ':')
+			buf = this.End.AppendA1(buf)
+		}
+	}
+	return string(buf)
+}
+
+func appendAbs(dst []byte, abs bool) []byte {
+	if abs {
+		return append(dst, '$')
+	}
+	return dst
+}
+
+//拆分带工作表名称的引用，如Sheet1!B3:H500、'My ''Sheet'''!A1，没有工作表名称时sheet 为空
+func SplitSheet(s string) (sheet, ref string) {
+	i := strings.LastIndexByte(s, '!')
+	if i < 0 {
+		return "", s
+	}
+	sheet, ref = s[:i], s[i+1:]
+	if len(sheet) >= 2 && sheet[0] == '\'' && sheet[len(sheet)-1] == '\'' {
+		sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+	return
+}
@@ -0,0 +1,134 @@
+package cellref
+
+import (
+	"testing"
+)
+
+func TestColumn(t *testing.T) {
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA", 16383: "XFD"} {
+		if got := ColumnName(i); got != name {
+			t.Errorf("ColumnName(%d)=%s want %s", i, got, name)
+		}
+		if got, err := ColumnIndex(name); err != nil || got != i {
+			t.Errorf("ColumnIndex(%s)=%d,%v want %d", name, got, err, i)
+		}
+	}
+	if got, _ := ColumnIndex("xfd"); got != 16383 {
+		t.Errorf("lowercase=%d", got)
+	}
+	for _, name := range []string{"", "XFE", "AAAA", "A1", "中"} {
+		if _, err := ColumnIndex(name); err != ErrColumn {
+			t.Errorf("ColumnIndex(%q) err=%v", name, err)
+		}
+	}
+}
+
+func TestParseA1(t *testing.T) {
+	cases := map[string]Ref{
+		"B7":         {Col: 1, Row: 7},
+		"$B$7":       {Col: 1, Row: 7, ColAbs: true, RowAbs: true},
+		"AA10":       {Col: 26, Row: 10},
+		"b$7":        {Col: 1, Row: 7, RowAbs: true},
+		"XFD1048576": {Col: 16383, Row: 1048576},
+	}
+	for s, want := range cases {
+		ref, err := ParseA1(s)
+		if err != nil || ref != want {
+			t.Errorf("ParseA1(%s)=%+v,%v want %+v", s, ref, err, want)
+		}
+	}
+	if got := (Ref{Col: 1, Row: 7, ColAbs: true, RowAbs: true}).String(); got != "$B$7" {
+		t.Errorf("String=%s", got)
+	}
+	for _, s := range []string{"", "B", "7", "B0", "B07", "B1048577", "XFE1", "B7C", "$$B7"} {
+		if _, err := ParseA1(s); err != ErrRef {
+			t.Errorf("ParseA1(%q) err=%v", s, err)
+		}
+	}
+}
+
+func TestParseA1Allocs(t *testing.T) {
+	n := testing.AllocsPerRun(100, func() {
+		ParseA1("AB1234")
+	})
+	if n != 0 {
+		t.Errorf("allocs=%v", n)
+	}
+	buf := make([]byte, 0, 16)
+	n = testing.AllocsPerRun(100, func() {
+		buf = Ref{Col: 27, Row: 99}.AppendA1(buf[:0])
+	})
+	if n != 0 {
+		t.Errorf("append allocs=%v", n)
+	}
+}
+
+func TestR1C1(t *testing.T) {
+	base := Ref{Col: 2, Row: 5} //C5
+	cases := map[string]Ref{
+		"R7C2":      {Col: 1, Row: 7, ColAbs: true, RowAbs: true},
+		"R[-1]C[2]": {Col: 4, Row: 4},
+		"RC":        {Col: 2, Row: 5},
+		"R1C[-2]":   {Col: 0, Row: 1, RowAbs: true},
+	}
+	for s, want := range cases {
+		ref, err := ParseR1C1(s, base)
+		if err != nil || ref != want {
+			t.Errorf("ParseR1C1(%s)=%+v,%v want %+v", s, ref, err, want)
+		}
+		if got := ref.R1C1(base); got != s {
+			t.Errorf("R1C1=%s want %s", got, s)
+		}
+	}
+	for _, s := range []string{"", "R", "C1", "R0C1", "R[-5]C1", "R1C[x]", "R-1C1"} {
+		if _, err := ParseR1C1(s, base); err != ErrRef {
+			t.Errorf("ParseR1C1(%q) err=%v", s, err)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	cases := map[string]struct {
+		rng  Range
+		text string
+	}{
+		"B2:F100": {Range{Ref{Col: 1, Row: 2}, Ref{Col: 5, Row: 100}}, "B2:F100"},
+		"F100:B2": {Range{Ref{Col: 1, Row: 2}, Ref{Col: 5, Row: 100}}, "B2:F100"},
+		"C:E":     {Range{Ref{Col: 2, Row: 1}, Ref{Col: 4, Row: MaxRows}}, "C:E"},
+		"$C:$E":   {Range{Ref{Col: 2, Row: 1, ColAbs: true}, Ref{Col: 4, Row: MaxRows, ColAbs: true}}, "$C:$E"},
+		"3:9":     {Range{Ref{Col: 0, Row: 3}, Ref{Col: MaxColumns - 1, Row: 9}}, "3:9"},
+		"B2":      {Range{Ref{Col: 1, Row: 2}, Ref{Col: 1, Row: 2}}, "B2"},
+	}
+	for s, want := range cases {
+		rng, err := ParseRange(s)
+		if err != nil || rng != want.rng {
+			t.Errorf("ParseRange(%s)=%+v,%v want %+v", s, rng, err, want.rng)
+		}
+		if got := rng.String(); got != want.text {
+			t.Errorf("String=%s want %s", got, want.text)
+		}
+	}
+	rng, _ := ParseRange("B2:F100")
+	if !rng.Contains(1, 2) || !rng.Contains(5, 100) || rng.Contains(0, 2) || rng.Contains(1, 101) || rng.Cols() != 5 || rng.Rows() != 99 {
+		t.Errorf("range=%+v", rng)
+	}
+	for _, s := range []string{"", "A:3", "B2:C", "3:B2", "B2:", ":", "A1:B2:C3"} {
+		if _, err := ParseRange(s); err != ErrRange {
+			t.Errorf("ParseRange(%q) err=%v", s, err)
+		}
+	}
+}
+
+func TestSplitSheet(t *testing.T) {
+	cases := map[string][2]string{
+		"Sheet1!B3:H500":    {"Sheet1", "B3:H500"},
+		"'My ''Sheet'''!A1": {"My 'Sheet'", "A1"},
+		"'会员 名单'!C:E":       {"会员 名单", "C:E"},
+		"B3:H500":           {"", "B3:H500"},
+	}
+	for s, want := range cases {
+		if sheet, ref := SplitSheet(s); sheet != want[0] || ref != want[1] {
+			t.Errorf("SplitSheet(%s)=%s,%s", s, sheet, ref)
+		}
+	}
+}
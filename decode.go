@@ -0,0 +1,350 @@
+package xlsx_reader
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+var (
+	ErrRequired   = errors.New("Value is required")
+	ErrStructType = errors.New("Value must be a non-nil pointer to struct")
+)
+
+//自定义类型实现该接口后由自己解析单元格
+type CellUnmarshaler interface {
+	UnmarshalCell(cell Cell) error
+}
+
+//单元格转换错误，包含单元格引用
+type CellError struct {
+	Sheet  string //工作表名称，校验错误时有值
+	Ref    string //单元格引用，如B7，工作表中不存在的列为空
+	Row    int    //从1开始的行号
+	Col    int    //从0开始的列序号，工作表中不存在的列为-1
+	Column string //列名
+	Value  string //单元格文本
+	Err    error
+}
+
+func (this *CellError) Error() string {
+	if this.Sheet != "" {
+		return fmt.Sprintf("%s!%s[%s] row %d %q: %v", this.Sheet, this.Ref, this.Column, this.Row, this.Value, this.Err)
+	}
+	return fmt.Sprintf("%s[%s] %q: %v", this.Ref, this.Column, this.Value, this.Err)
+}
+
+func (this *CellError) Unwrap() error {
+	return this.Err
+}
+
+func newCellError(c Cell, column string, err error) *CellError {
+	e := &CellError{Row: c.Row, Col: c.Col, Column: column, Value: c.Raw, Err: err}
+	if c.Col >= 0 {
+		e.Ref = cellref.ColumnName(c.Col) + strconv.Itoa(c.Row)
+	}
+	return e
+}
+
+//一行中所有单元格的转换错误
+type RowError []*CellError
+
+func (this RowError) Error() string {
+	msgs := make([]string, len(this))
+	for i, e := range this {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+//结构体字段与列的对应关系
+type structField struct {
+	index    []int //reflect.Value.FieldByIndex 的参数
+	col      int   //在cols 中的位置
+	required bool
+}
+
+type structPlan struct {
+	typ    reflect.Type
+	fields []structField
+}
+
+//解析结构体的xlsx 标签，如`xlsx:"会员昵称,required"`，没有标签时使用字段名，"-" 忽略该字段
+//匿名结构体及导出的匿名结构体指针的字段展开，指针为nil 时在解码时分配；未导出类型的匿名指针无法分配，忽略
+func (this *reader) newStructPlan(typ reflect.Type) (*structPlan, error) {
+	plan := &structPlan{typ: typ}
+	used := make([]bool, len(this.cols))
+	walking := map[reflect.Type]bool{typ: true} //避免type A struct{ *A } 无限展开
+	var walk func(t reflect.Type, index []int) error
+	walk = func(t reflect.Type, index []int) error {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("xlsx")
+			if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+				continue
+			}
+			idx := append(append([]int(nil), index...), i)
+			//匿名结构体字段展开
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+				if err := walk(f.Type, idx); err != nil {
+					return err
+				}
+				continue
+			}
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
+				elem := f.Type.Elem()
+				if f.PkgPath != "" || walking[elem] {
+					continue
+				}
+				walking[elem] = true
+				err := walk(elem, idx)
+				delete(walking, elem)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			name := parts[0]
+			if name == "" {
+				name = f.Name
+			}
+			field := structField{index: idx, col: -1}
+			for _, opt := range parts[1:] {
+				if opt == "required" {
+					field.required = true
+				}
+			}
+			//同名的列依次对应多个使用该列名的字段
+			for j, c := range this.cols {
+				if c == name && !used[j] {
+					field.col = j
+					used[j] = true
+					break
+				}
+			}
+			if field.col == -1 {
+				if field.required {
+					return &HeaderError{Missing: []string{name}}
+				}
+				continue
+			}
+			plan.fields = append(plan.fields, field)
+		}
+		return nil
+	}
+	if err := walk(typ, nil); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+//逐行解码到结构体，v 为结构体指针，每行解码前重置为零值，firstRowIsCol 必须为true
+//单元格转换失败不会中断读取，rowErr 为RowError，如果rowAction中返回 err!=nil 则中断
+func (this *reader) FetchStruct(v interface{}, rowAction func(rowErr error) error) error {
+	if err := this.prepareStruct(v); err != nil {
+		return err
+	}
+	for this.Next() {
+		errs, err := this.scan(v)
+		if err != nil {
+			return err
+		}
+		var rowErr error
+		if len(errs) > 0 {
+			rowErr = errs
+		}
+		if err = rowAction(rowErr); err != nil {
+			return err
+		}
+	}
+	return this.Err()
+}
+
+//校验v 的类型并解析字段与列的对应关系
+func (this *reader) prepareStruct(v interface{}) error {
+	if !this.firstRowIsCol {
+		return errors.New("firstRowIsCol must be true")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrStructType
+	}
+	if this.structPlan == nil || this.structPlan.typ != rv.Elem().Type() {
+		plan, err := this.newStructPlan(rv.Elem().Type())
+		if err != nil {
+			return err
+		}
+		this.structPlan = plan
+	}
+	return nil
+}
+
+//将一行单元格解码到结构体
+func (this *reader) decodeStruct(cells []Cell, rv reflect.Value) RowError {
+	rv.Set(reflect.Zero(rv.Type()))
+	var errs RowError
+	for _, f := range this.structPlan.fields {
+		c := cells[f.col]
+		var err error
+		if c.Kind == KindEmpty || (c.Kind == KindString && strings.TrimSpace(c.Raw) == "") {
+			if f.required {
+				err = ErrRequired
+			}
+		} else {
+			err = this.setField(fieldByIndex(rv, f.index), c)
+		}
+		if err != nil {
+			errs = append(errs, newCellError(c, this.cols[f.col], err))
+		}
+	}
+	return errs
+}
+
+//同reflect.Value.FieldByIndex，经过的nil 匿名结构体指针分配新值
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	cellUnmarshalerTyp = reflect.TypeOf((*CellUnmarshaler)(nil)).Elem()
+	textUnmarshalerTyp = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+//将单元格转换为字段的类型
+func (this *reader) setField(fv reflect.Value, c Cell) error {
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := this.setField(ptr.Elem(), c); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if fv.Type() == timeType {
+		t, err := this.cellTime(c)
+		if err == nil {
+			fv.Set(reflect.ValueOf(t))
+		}
+		return err
+	}
+	if fv.CanAddr() {
+		switch addr := fv.Addr(); {
+		case addr.Type().Implements(cellUnmarshalerTyp):
+			return addr.Interface().(CellUnmarshaler).UnmarshalCell(c)
+		case addr.Type().Implements(textUnmarshalerTyp):
+			return addr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(c.Raw))
+		}
+	}
+	s := strings.TrimSpace(c.Raw)
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(this.cellText(c))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil || f != float64(int64(f)) {
+				return err
+			}
+			n = int64(f)
+		}
+		if fv.OverflowInt(n) {
+			return strconv.ErrRange
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil || f < 0 || f != float64(uint64(f)) {
+				return err
+			}
+			n = uint64(f)
+		}
+		if fv.OverflowUint(n) {
+			return strconv.ErrRange
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		if fv.OverflowFloat(f) {
+			return strconv.ErrRange
+		}
+		fv.SetFloat(f)
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+//除strconv.ParseBool 支持的格式外，还支持是/否、yes/no、y/n
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "是", "yes", "y", "√":
+		return true, nil
+	case "否", "no", "n", "×":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+//文本格式的日期
+var timeLayouts = []string{
+	"2006-01-02", "2006/1/2", "2006-1-2", "2006.1.2", "2006年1月2日", "20060102",
+	"2006-01-02 15:04:05", "2006/1/2 15:04:05", "2006-1-2 15:04:05", "2006/1/2 15:04", "2006-01-02 15:04",
+	"2006-01-02T15:04:05Z07:00",
+}
+
+//日期单元格、数值及常见的文本日期格式转换为time.Time
+func (this *reader) cellTime(c Cell) (time.Time, error) {
+	switch c.Kind {
+	case KindDate:
+		if c.Value == nil {
+			c.parse(this.workbook.date1904)
+		}
+		if t, ok := c.Time(); ok {
+			return t, nil
+		}
+	case KindNumber:
+		if f, err := strconv.ParseFloat(c.Raw, 64); err == nil {
+			return GetExcelTime(f, this.workbook.date1904), nil
+		}
+	}
+	return parseTime(c.Raw)
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
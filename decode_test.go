@@ -0,0 +1,150 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+//性别：男/女
+type gender int
+
+func (this *gender) UnmarshalCell(cell Cell) error {
+	switch cell.Raw {
+	case "男":
+		*this = 1
+	case "女":
+		*this = 2
+	default:
+		return errors.New("invalid gender")
+	}
+	return nil
+}
+
+type memberBase struct {
+	Mobile string `xlsx:"*手机号码,required"`
+}
+
+type member struct {
+	memberBase
+	Nickname string     `xlsx:"会员昵称"`
+	Gender   gender     `xlsx:"性别"`
+	Birthday time.Time  `xlsx:"出生日期"`
+	Points   int        `xlsx:"积分"`
+	Rate     *float64   `xlsx:"折扣"`
+	VIP      bool       `xlsx:"VIP"`
+	Expire   *time.Time `xlsx:"积分有效期"`
+	Ignored  string     `xlsx:"-"`
+	note     string
+}
+
+var memberFixture = fixture{
+	sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1">` +
+		`<c r="A1" t="inlineStr"><is><t>*手机号码</t></is></c><c r="B1" t="inlineStr"><is><t>会员昵称</t></is></c>` +
+		`<c r="C1" t="inlineStr"><is><t>性别</t></is></c><c r="D1" t="inlineStr"><is><t>出生日期</t></is></c>` +
+		`<c r="E1" t="inlineStr"><is><t>积分</t></is></c><c r="F1" t="inlineStr"><is><t>折扣</t></is></c>` +
+		`<c r="G1" t="inlineStr"><is><t>VIP</t></is></c><c r="H1" t="inlineStr"><is><t>积分有效期</t></is></c></row>` +
+		`<row r="2"><c r="A2"><v>13800138000</v></c><c r="B2" t="inlineStr"><is><t>张三</t></is></c>` +
+		`<c r="C2" t="inlineStr"><is><t>男</t></is></c><c r="D2" s="1"><v>36526</v></c><c r="E2"><v>100</v></c>` +
+		`<c r="F2"><v>0.85</v></c><c r="G2" t="b"><v>1</v></c><c r="H2" t="inlineStr"><is><t>2025/1/31</t></is></c></row>` +
+		`<row r="3"><c r="B3" t="inlineStr"><is><t>李四</t></is></c><c r="C3" t="inlineStr"><is><t>未知</t></is></c>` +
+		`<c r="E3"><v>1.5</v></c><c r="G3" t="inlineStr"><is><t>否</t></is></c></row>`}},
+	files: dateFixture.files,
+}
+
+func TestReader_FetchStruct(t *testing.T) {
+	r := ReaderFromBytes(memberFixture.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var m member
+	var members []member
+	var errs []RowError
+	err := r.FetchStruct(&m, func(rowErr error) error {
+		members = append(members, m)
+		if rowErr != nil {
+			errs = append(errs, rowErr.(RowError))
+		} else {
+			errs = append(errs, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("members=%v", members)
+	}
+	m1 := members[0]
+	if m1.Mobile != "13800138000" || m1.Nickname != "张三" || m1.Gender != 1 || m1.Points != 100 || !m1.VIP ||
+		m1.Rate == nil || *m1.Rate != 0.85 || !m1.Birthday.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		m1.Expire == nil || !m1.Expire.Equal(time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)) || errs[0] != nil {
+		t.Errorf("member 1=%+v errs=%v", m1, errs[0])
+	}
+	m2 := members[1]
+	if m2.Nickname != "李四" || m2.VIP || m2.Rate != nil || m2.Expire != nil {
+		t.Errorf("member 2=%+v", m2)
+	}
+	var refs []string
+	for _, e := range errs[1] {
+		refs = append(refs, e.Ref)
+	}
+	if strings.Join(refs, ",") != "A3,C3,E3" || !errors.Is(errs[1][0], ErrRequired) || errs[1][1].Column != "性别" {
+		t.Errorf("errs=%v", errs[1])
+	}
+}
+
+type MemberContact struct {
+	Mobile   string `xlsx:"*手机号码,required"`
+	Nickname string `xlsx:"会员昵称"`
+}
+
+type memberPtr struct {
+	*MemberContact
+	Points float64 `xlsx:"积分"`
+}
+
+func TestReader_FetchStructEmbeddedPointer(t *testing.T) {
+	r := ReaderFromBytes(memberFixture.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var m memberPtr
+	var members []memberPtr
+	var rowErrs []error
+	err := r.FetchStruct(&m, func(rowErr error) error {
+		members = append(members, m)
+		rowErrs = append(rowErrs, rowErr)
+		return nil
+	})
+	if err != nil || len(members) != 2 {
+		t.Fatalf("members=%v err=%v", members, err)
+	}
+	//每行分配新的指针
+	m1, m2 := members[0], members[1]
+	if m1.MemberContact == nil || m1.Mobile != "13800138000" || m1.Nickname != "张三" || m1.Points != 100 || rowErrs[0] != nil {
+		t.Errorf("member 1=%+v err=%v", m1, rowErrs[0])
+	}
+	if m2.MemberContact == nil || m2.MemberContact == m1.MemberContact || m2.Nickname != "李四" || rowErrs[1] == nil || !errors.Is(rowErrs[1].(RowError)[0], ErrRequired) {
+		t.Errorf("member 2=%+v err=%v", m2, rowErrs[1])
+	}
+}
+
+func TestReader_FetchStructMissingColumn(t *testing.T) {
+	r := ReaderFromBytes(simpleFixture.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var m member
+	err := r.FetchStruct(&m, func(error) error { return nil })
+	if !errors.Is(err, ErrCols) {
+		t.Errorf("err=%v", err)
+	}
+	if err = r.FetchStruct(m, nil); err != ErrStructType {
+		t.Errorf("err=%v", err)
+	}
+}
@@ -98,3 +98,21 @@ func GetExcelTime(excelTime float64, date1904 bool) time.Time {
 	durationPart := time.Duration(dayNanoSeconds * floatPart)
 	return date.Add(durationDays).Add(durationPart)
 }
+
+//time类型转换为Excel float类型日期时间，按t 所在时区的日期及时间计算
+//1900 日期系统中1900-03-01 之前的日期按Excel 的规则(1900 年视为闰年)减1
+func ExcelSerial(t time.Time, date1904 bool) float64 {
+	utc := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	secs := utc.Unix() - epoch.Unix()
+	days := math.Floor(float64(secs) / 86400)
+	rest := float64(secs) - days*86400 + float64(utc.Nanosecond())/1e9
+	serial := days + rest/86400
+	if !date1904 && utc.Before(time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		serial--
+	}
+	return serial
+}
@@ -0,0 +1,97 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+//测试用的工作表，sheetData 为<sheetData>内的xml
+type fixtureSheet struct {
+	name      string
+	sheetData string
+	state     string //可见状态
+	head      string //<sheetData>之前的xml，如<dimension>
+	tail      string //<sheetData>之后的xml，如<mergeCells>
+}
+
+//在内存中构造一个最小的xlsx 文件
+type fixture struct {
+	sheets   []fixtureSheet
+	strings  []string          //共享字符串
+	files    map[string]string //其他文件，如xl/styles.xml
+	bookRels string            //workbook.xml.rels 中追加的关系，如calcChain
+	date1904 bool
+}
+
+func (this fixture) bytes(t testing.TB) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sheets, rels strings.Builder
+	for i, s := range this.sheets {
+		state := ""
+		if s.state != "" {
+			state = ` state="` + s.state + `"`
+		}
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d"%s r:id="rId%d"/>`, s.name, i+1, state, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		write(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+s.head+`<sheetData>`+s.sheetData+`</sheetData>`+s.tail+`</worksheet>`)
+	}
+	workbookPr := ""
+	if this.date1904 {
+		workbookPr = `<workbookPr date1904="1"/>`
+	}
+	write("xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		workbookPr+`<sheets>`+sheets.String()+`</sheets></workbook>`)
+	write("xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+rels.String()+this.bookRels+`</Relationships>`)
+	if this.strings != nil {
+		var sst strings.Builder
+		fmt.Fprintf(&sst, `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="%d" uniqueCount="%d">`, len(this.strings), len(this.strings))
+		for _, s := range this.strings {
+			sst.WriteString(`<si><t>` + s + `</t></si>`)
+		}
+		sst.WriteString(`</sst>`)
+		write("xl/sharedStrings.xml", sst.String())
+	}
+	for name, content := range this.files {
+		write(name, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+//读取全部行
+func fetchAll(t testing.TB, r *reader) [][]string {
+	var rows [][]string
+	err := r.FetchRow(func(row []string) error {
+		rows = append(rows, append([]string(nil), row...))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+//简单的两行工作表：表头+一行数据
+var simpleFixture = fixture{
+	sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>18</v></c></row>`}},
+	strings: []string{"姓名", "年龄", "张三"},
+}
@@ -1,3 +1,3 @@
 module github.com/fcodetop/xlsx-reader
 
-go 1.13
+go 1.17
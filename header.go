@@ -0,0 +1,311 @@
+package xlsx_reader
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+var ErrHeader = errors.New("Could not find header row")
+
+const (
+	DefaultHeaderScanRows = 10  //自动识别列名行时默认扫描的行数
+	HeaderSeparator       = "/" //多行列名中各行名称的分隔符
+)
+
+//列名所在的行号，从1开始，需在Open 之前调用，firstRowIsCol 为true 时有效
+//默认为0，即第一个<row>；列名行之前的说明文字等行被忽略
+func (this *reader) SetHeaderRow(row int) {
+	this.headerRow = row
+	this.headerScan = 0
+}
+
+//自动识别列名行，需在Open 之前调用，firstRowIsCol 为true 时有效
+//在前maxRows 行(小于等于0 时为DefaultHeaderScanRows)中查找：
+//使用OpenAndValidCols、OpenAndValidSpec、OpenAndMatchTemplate 时为第一个包含全部必须列的行，否则为第一个至少有两列、中间没有空列且全部为文本的行
+func (this *reader) SetDetectHeader(maxRows int) {
+	if maxRows <= 0 {
+		maxRows = DefaultHeaderScanRows
+	}
+	this.headerScan = maxRows
+	this.headerRow = 0
+}
+
+//重复列名的处理方式
+type DuplicateNames int
+
+const (
+	DupKeep   = DuplicateNames(0) //保持原样，按位置区分：HeaderSpec 或结构体中同一列名出现多次时依次对应第1、第2...个同名列
+	DupError  = DuplicateNames(1) //Open 返回HeaderError
+	DupSuffix = DuplicateNames(2) //第2个及以后的同名列加后缀，如"备注"、"备注_2"、"备注_3"
+)
+
+//空列名的处理方式
+type BlankNames int
+
+const (
+	BlankKeep   = BlankNames(0) //保持为空字符串
+	BlankError  = BlankNames(1) //Open 返回HeaderError
+	BlankLetter = BlankNames(2) //使用列字母，如"D"
+)
+
+//重复列名的处理方式，需在Open 之前调用，默认为DupKeep
+func (this *reader) SetDuplicateNames(s DuplicateNames) {
+	this.dupNames = s
+}
+
+//列名为空的列(位于有列名的列之间)的处理方式，需在Open 之前调用，默认为BlankKeep
+func (this *reader) SetBlankNames(s BlankNames) {
+	this.blankNames = s
+}
+
+//按dupNames 与blankNames 处理列名，cols 会被修改
+func (this *reader) resolveNames(cols []string) ([]string, error) {
+	report := &HeaderError{}
+	for i, c := range cols {
+		if strings.TrimSpace(c) != "" {
+			continue
+		}
+		switch this.blankNames {
+		case BlankError:
+			report.Blank = append(report.Blank, cellref.ColumnName(this.colOffset()+i))
+		case BlankLetter:
+			cols[i] = cellref.ColumnName(this.colOffset() + i)
+		}
+	}
+	seen := make(map[string]bool, len(cols))
+	for i, c := range cols {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		if !seen[c] {
+			seen[c] = true
+			continue
+		}
+		switch this.dupNames {
+		case DupError:
+			report.Duplicates = append(report.Duplicates, c)
+		case DupSuffix:
+			for n := 2; ; n++ {
+				name := c + "_" + strconv.Itoa(n)
+				if !seen[name] && !contains(cols[i+1:], name) {
+					cols[i] = name
+					seen[name] = true
+					break
+				}
+			}
+		}
+	}
+	if len(report.Blank) > 0 || len(report.Duplicates) > 0 {
+		return cols, report
+	}
+	return cols, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+//列名占用的行数，需在Open 之前调用，firstRowIsCol 为true 时有效
+//rows 大于1 时从列名行开始连续读取rows 行，合并单元格的值填充到整个合并区域，
+//每列各行的名称去掉空值及上下重复后用HeaderSeparator 连接，如"收入/Q1"
+func (this *reader) SetHeaderRows(rows int) {
+	this.headerRows = rows
+}
+
+//读取列名行，之前的行被忽略，读取后当前行为列名行
+func (this *reader) readHeader() ([]string, error) {
+	for {
+		ok, err := this.readRow()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if this.headerRow > 0 || this.headerScan > 0 {
+				return nil, ErrHeader
+			}
+			return nil, nil
+		}
+		switch {
+		case this.headerScan > 0:
+			if this.rowNum > this.headerScan { //按工作表行号计算，缺少的空行也计入
+				return nil, ErrHeader
+			}
+			if !this.isHeader() {
+				continue
+			}
+		case this.headerRow > 0:
+			if this.rowNum < this.headerRow {
+				continue
+			}
+			if this.rowNum > this.headerRow {
+				return nil, ErrHeader
+			}
+		}
+		this.curRow = this.rowNum
+		if this.headerRows > 1 {
+			return this.readHeaderRows()
+		}
+		return this.denseRow(), nil
+	}
+}
+
+//从当前行开始读取headerRows 行列名，读取到的下一行数据留给Next 输出
+func (this *reader) readHeaderRows() ([]string, error) {
+	first, last := this.rowNum, this.rowNum+this.headerRows-1
+	grid := make([][]string, this.headerRows)
+	grid[0] = this.denseRow()
+	for {
+		ok, err := this.readRow()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			this.iterDone = true
+			break
+		}
+		if this.rowNum > last {
+			this.pending = true
+			break
+		}
+		grid[this.rowNum-first] = this.denseRow()
+	}
+	this.curRow = last
+	merges, err := this.mergeCells()
+	if err != nil {
+		return nil, err
+	}
+	cols := flattenHeader(grid, first, this.colOffset(), merges)
+	if this.rng != nil && len(cols) > this.rng.Cols() {
+		cols = cols[:this.rng.Cols()]
+	}
+	return cols, nil
+}
+
+//合并单元格的值填充到合并区域中位于grid 内的单元格，再按列连接各行的名称
+//
+//first:grid 第一行的行号，off:grid 第一列的列序号
+func flattenHeader(grid [][]string, first, off int, merges []cellref.Range) []string {
+	width := 0
+	for _, row := range grid {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	last := first + len(grid) - 1
+	for _, m := range merges {
+		if m.Start.Row >= first && m.Start.Row <= last && m.Start.Col >= off && m.End.Col-off+1 > width {
+			width = m.End.Col - off + 1
+		}
+	}
+	for i := range grid {
+		for len(grid[i]) < width {
+			grid[i] = append(grid[i], "")
+		}
+	}
+	for _, m := range merges {
+		//左上角不在列名行中的合并区域无法取值
+		if m.Start.Row < first || m.Start.Row > last || m.Start.Col < off || m.Start.Col-off >= width {
+			continue
+		}
+		v := grid[m.Start.Row-first][m.Start.Col-off]
+		for r := m.Start.Row; r <= m.End.Row && r <= last; r++ {
+			for c := m.Start.Col; c <= m.End.Col && c-off < width; c++ {
+				grid[r-first][c-off] = v
+			}
+		}
+	}
+	cols := make([]string, width)
+	for c := 0; c < width; c++ {
+		var parts []string
+		for r := range grid {
+			v := strings.TrimSpace(grid[r][c])
+			if v != "" && (len(parts) == 0 || parts[len(parts)-1] != v) {
+				parts = append(parts, v)
+			}
+		}
+		cols[c] = strings.Join(parts, HeaderSeparator)
+	}
+	return cols
+}
+
+//工作表中的合并单元格，<mergeCells>位于<sheetData>之后，需要重新打开工作表并跳过<sheetData>
+func (this *reader) mergeCells() ([]cellref.Range, error) {
+	rc, err := this.sheetData.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "sheetData":
+			if err = decoder.Skip(); err != nil {
+				return nil, err
+			}
+		case "mergeCells":
+			var mc xlsxMergeCells
+			if err = decoder.DecodeElement(&mc, &start); err != nil {
+				return nil, err
+			}
+			merges := make([]cellref.Range, 0, len(mc.Cells))
+			for _, c := range mc.Cells {
+				if rng, err := cellref.ParseRange(c.Ref); err == nil {
+					merges = append(merges, rng)
+				}
+			}
+			return merges, nil
+		}
+	}
+}
+
+//当前行是否像列名行
+func (this *reader) isHeader() bool {
+	cells := this.rowCells
+	if len(this.headerSpecs) > 0 {
+		header := make([]string, 0, len(cells))
+		for _, c := range cells {
+			if c.Kind == KindString {
+				header = append(header, c.Raw)
+			}
+		}
+		for _, spec := range this.headerSpecs {
+			if _, missing := spec.match(header); len(missing) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if len(cells) < 2 {
+		return false
+	}
+	for i, c := range cells {
+		if c.Kind != KindString || strings.TrimSpace(c.Raw) == "" {
+			return false
+		}
+		if i > 0 && c.Col != cells[i-1].Col+1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//列名之前有两行说明
+func headerFixture() fixture {
+	return fixture{strings: []string{"供应商导入模板", "请勿修改列名", "编码", "名称", "数量"}, sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>1</v></c><c r="C2"><v>2020</v></c></row>` +
+		`<row r="4"><c r="A4" t="s"><v>2</v></c><c r="B4" t="s"><v>3</v></c><c r="C4" t="s"><v>4</v></c></row>` +
+		`<row r="5"><c r="A5"><v>1001</v></c><c r="B5" t="inlineStr"><is><t>螺丝</t></is></c><c r="C5"><v>8</v></c></row>`}}}
+}
+
+func TestReader_SetHeaderRow(t *testing.T) {
+	data := headerFixture().bytes(t)
+	r := ReaderFromBytes(data, "", true)
+	r.SetHeaderRow(4)
+	defer r.Close()
+	cols, err := r.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cols, []string{"编码", "名称", "数量"}) {
+		t.Errorf("cols=%q", cols)
+	}
+	if r.RowNumber() != 4 {
+		t.Errorf("header row=%d", r.RowNumber())
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"1001", "螺丝", "8"}}) {
+		t.Errorf("rows=%q", rows)
+	}
+
+	r = ReaderFromBytes(data, "", true)
+	r.SetHeaderRow(3)
+	defer r.Close()
+	if _, err = r.Open(); !errors.Is(err, ErrHeader) {
+		t.Errorf("missing header row: err=%v", err)
+	}
+}
+
+func TestReader_SetDetectHeader(t *testing.T) {
+	data := headerFixture().bytes(t)
+	r := ReaderFromBytes(data, "", true)
+	r.SetDetectHeader(0)
+	defer r.Close()
+	cols, err := r.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cols, []string{"编码", "名称", "数量"}) {
+		t.Errorf("cols=%q", cols)
+	}
+
+	r = ReaderFromBytes(data, "", true)
+	r.SetDetectHeader(5)
+	defer r.Close()
+	if err = r.OpenAndValidCols([]string{"数量", "编码"}); err != nil {
+		t.Fatal(err)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"8", "1001"}}) {
+		t.Errorf("rows=%q", rows)
+	}
+
+	//按行号计算，第4行为列名时前3行中没有列名，即使工作表中只有3 个<row>
+	for _, scan := range []int{2, 3} {
+		r = ReaderFromBytes(data, "", true)
+		r.SetDetectHeader(scan)
+		defer r.Close()
+		if _, err = r.Open(); !errors.Is(err, ErrHeader) {
+			t.Errorf("header beyond %d scan rows: err=%v", scan, err)
+		}
+	}
+	r = ReaderFromBytes(data, "", true)
+	r.SetDetectHeader(4)
+	defer r.Close()
+	if cols, err = r.Open(); err != nil || len(cols) != 3 {
+		t.Errorf("cols=%q err=%v", cols, err)
+	}
+}
+
+func TestReader_SetHeaderRows(t *testing.T) {
+	f := fixture{strings: []string{"部门", "收入", "Q1", "Q2", "备注"}, sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>4</v></c></row>` +
+		`<row r="2"><c r="B2" t="s"><v>2</v></c><c r="C2" t="s"><v>3</v></c></row>` +
+		`<row r="4"><c r="A4" t="inlineStr"><is><t>销售</t></is></c><c r="B4"><v>10</v></c><c r="C4"><v>20</v></c></row>`,
+		tail: `<mergeCells count="3"><mergeCell ref="A1:A2"/><mergeCell ref="B1:C1"/><mergeCell ref="D1:D2"/></mergeCells>`}}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	r.SetHeaderRows(2)
+	r.SetFillBlankRows(true)
+	defer r.Close()
+	if err := r.OpenAndValidCols([]string{"部门", "收入/Q2", "收入/Q1"}); err != nil {
+		t.Fatal(err)
+	}
+	var numbers []int
+	var rows [][]string
+	for r.Next() {
+		numbers = append(numbers, r.RowNumber())
+		rows = append(rows, r.Row())
+	}
+	if !reflect.DeepEqual(numbers, []int{3, 4}) || !reflect.DeepEqual(rows, [][]string{{"", "", ""}, {"销售", "20", "10"}}) {
+		t.Errorf("numbers=%v rows=%q", numbers, rows)
+	}
+
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	r.SetHeaderRows(2)
+	defer r.Close()
+	cols, err := r.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cols, []string{"部门", "收入/Q1", "收入/Q2", "备注"}) {
+		t.Errorf("cols=%q", cols)
+	}
+}
+
+func TestReader_DuplicateAndBlankNames(t *testing.T) {
+	f := fixture{strings: []string{"编码", "备注", "备注_2"}, sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c>` +
+		`<c r="D1" t="s"><v>1</v></c><c r="E1" t="s"><v>2</v></c></row>` +
+		`<row r="2"><c r="A2"><v>1</v></c><c r="B2"><v>2</v></c><c r="C2"><v>3</v></c><c r="D2"><v>4</v></c><c r="E2"><v>5</v></c></row>`}}}
+	cases := []struct {
+		dup   DuplicateNames
+		blank BlankNames
+		want  []string
+	}{
+		{DupKeep, BlankKeep, []string{"编码", "备注", "", "备注", "备注_2"}},
+		{DupSuffix, BlankLetter, []string{"编码", "备注", "C", "备注_3", "备注_2"}},
+	}
+	for _, c := range cases {
+		r := ReaderFromBytes(f.bytes(t), "", true)
+		r.SetDuplicateNames(c.dup)
+		r.SetBlankNames(c.blank)
+		cols, err := r.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cols, c.want) {
+			t.Errorf("%d %d: cols=%q", c.dup, c.blank, cols)
+		}
+		r.Close()
+	}
+
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	r.SetDuplicateNames(DupError)
+	r.SetBlankNames(BlankError)
+	defer r.Close()
+	_, err := r.Open()
+	var he *HeaderError
+	if !errors.As(err, &he) || !reflect.DeepEqual(he.Duplicates, []string{"备注"}) || !reflect.DeepEqual(he.Blank, []string{"C"}) {
+		t.Errorf("err=%v", err)
+	}
+
+	//同名的列按位置依次对应
+	var v struct {
+		First  string `xlsx:"备注"`
+		Second string `xlsx:"备注"`
+	}
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err = r.OpenAndValidSpec(HeaderSpec{Columns: []Column{{Name: "备注"}, {Name: "备注"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"2", "4"}}) {
+		t.Errorf("rows=%q", rows)
+	}
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if _, err = r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.FetchStruct(&v, func(rowErr error) error { return rowErr }); err != nil {
+		t.Fatal(err)
+	}
+	if v.First != "2" || v.Second != "4" {
+		t.Errorf("v=%+v", v)
+	}
+}
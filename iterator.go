@@ -0,0 +1,90 @@
+package xlsx_reader
+
+import (
+	"reflect"
+)
+
+//读取下一行，读取完毕或出错时返回false，出错原因通过Err 获取
+//与FetchRow 使用相同的解析状态，可以交替使用
+//
+//	for r.Next() {
+//		row := r.Row()
+//	}
+//	if err := r.Err(); err != nil {
+//	}
+func (this *reader) Next() bool {
+	if this.iterErr != nil || this.iterDone {
+		return false
+	}
+	if this.sheetXmlDecoder == nil {
+		this.iterErr = ErrNotOpen
+		return false
+	}
+	this.row, this.cells = nil, nil
+	if !this.pending {
+		ok, err := this.readRow()
+		if err != nil {
+			this.iterErr = err
+			return false
+		}
+		if !ok {
+			this.iterDone = true
+			return false
+		}
+	}
+	//已读取的行之前缺少的行以空行输出
+	if this.fillBlankRows && this.rowNum > this.curRow+1 {
+		this.curRow++
+		this.blankRow, this.pending = true, true
+		return true
+	}
+	this.curRow = this.rowNum
+	this.blankRow, this.pending = false, false
+	this.validate()
+	return true
+}
+
+//当前行，同FetchRow 中的row
+func (this *reader) Row() []string {
+	if this.row == nil {
+		this.row = this.stringRow()
+	}
+	return this.row
+}
+
+//当前行带类型的单元格，同FetchCells 中的cells
+func (this *reader) Cells() []Cell {
+	if this.cells == nil {
+		this.cells = this.cellRow()
+	}
+	return this.cells
+}
+
+//当前行在工作表中的行号，从1开始
+func (this *reader) RowNumber() int {
+	return this.curRow
+}
+
+//Next 返回false 的原因，正常读取完毕时为nil
+func (this *reader) Err() error {
+	return this.iterErr
+}
+
+//将当前行解码到结构体，v 为结构体指针，单元格转换失败时返回RowError
+func (this *reader) Scan(v interface{}) error {
+	errs, err := this.scan(v)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (this *reader) scan(v interface{}) (RowError, error) {
+	if err := this.prepareStruct(v); err != nil {
+		return nil, err
+	}
+	return this.decodeStruct(this.Cells(), reflect.ValueOf(v).Elem()), nil
+}
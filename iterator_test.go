@@ -0,0 +1,115 @@
+package xlsx_reader
+
+import (
+	"reflect"
+	"testing"
+)
+
+var numbersFixture = fixture{
+	sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>n</t></is></c></row>` +
+		`<row r="2"><c r="A2"><v>1</v></c></row><row r="3"><c r="A3"><v>2</v></c></row>` +
+		`<row r="5"><c r="A5"><v>3</v></c></row><row r="6"><c r="A6"><v>4</v></c></row>`}},
+}
+
+func TestReader_Next(t *testing.T) {
+	r := ReaderFromBytes(numbersFixture.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	//读取两行后停止，不需要哨兵错误
+	var got [][]string
+	var nums []int
+	for i := 0; i < 2 && r.Next(); i++ {
+		got = append(got, r.Row())
+		nums = append(nums, r.RowNumber())
+		if c := r.Cells(); c[0].Value != float64(i+1) {
+			t.Errorf("cells=%v", c)
+		}
+	}
+	//剩余的行用FetchRow 读取
+	rest := fetchAll(t, r)
+	if !reflect.DeepEqual(got, [][]string{{"1"}, {"2"}}) || !reflect.DeepEqual(rest, [][]string{{"3"}, {"4"}}) {
+		t.Errorf("got=%v rest=%v", got, rest)
+	}
+	if !reflect.DeepEqual(nums, []int{2, 3}) {
+		t.Errorf("row numbers=%v", nums)
+	}
+	if r.Next() || r.Err() != nil {
+		t.Errorf("next after end, err=%v", r.Err())
+	}
+}
+
+func TestReader_NextScan(t *testing.T) {
+	r := ReaderFromBytes(memberFixture.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var m member
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	if err := r.Scan(&m); err != nil || m.Nickname != "张三" {
+		t.Errorf("m=%+v err=%v", m, err)
+	}
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	if errs, ok := r.Scan(&m).(RowError); !ok || len(errs) != 3 {
+		t.Errorf("errs=%v", errs)
+	}
+}
+
+func TestReader_NextNotOpen(t *testing.T) {
+	r := ReaderFromBytes(nil, "", false)
+	if r.Next() || r.Err() != ErrNotOpen {
+		t.Errorf("err=%v", r.Err())
+	}
+}
+
+func TestReader_FetchRowWithNumber(t *testing.T) {
+	for _, fill := range []bool{false, true} {
+		r := ReaderFromBytes(numbersFixture.bytes(t), "", true)
+		r.SetFillBlankRows(fill)
+		if _, err := r.Open(); err != nil {
+			t.Fatal(err)
+		}
+		var nums []int
+		var rows [][]string
+		err := r.FetchRowWithNumber(func(n int, row []string) error {
+			nums = append(nums, n)
+			rows = append(rows, row)
+			return nil
+		})
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantNums, wantRows := []int{2, 3, 5, 6}, [][]string{{"1"}, {"2"}, {"3"}, {"4"}}
+		if fill {
+			wantNums, wantRows = []int{2, 3, 4, 5, 6}, [][]string{{"1"}, {"2"}, {""}, {"3"}, {"4"}}
+		}
+		if !reflect.DeepEqual(nums, wantNums) || !reflect.DeepEqual(rows, wantRows) {
+			t.Errorf("fill=%v nums=%v rows=%v", fill, nums, rows)
+		}
+	}
+}
+
+func TestReader_FillBlankRowsWithoutHeader(t *testing.T) {
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="3"><c r="B3"><v>1</v></c></row>`}}}
+	r := ReaderFromBytes(f.bytes(t), "", false)
+	r.SetFillBlankRows(true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var cells [][]Cell
+	for r.Next() {
+		cells = append(cells, r.Cells())
+	}
+	want := [][]Cell{{}, {}, {{Row: 3, Col: 0}, {Row: 3, Col: 1, Kind: KindNumber, Raw: "1", Value: float64(1)}}}
+	if !reflect.DeepEqual(cells, want) {
+		t.Errorf("cells=%v", cells)
+	}
+}
@@ -0,0 +1,1033 @@
+package xlsx_reader
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+//数字格式中的标记类型
+const (
+	fmtLiteral = iota //原样输出的文本
+	fmtDigit          //数字占位符 0 # ?
+	fmtPoint          //小数点
+	fmtComma          //千位分隔符或缩放，解析后确定
+	fmtPercent
+	fmtExp      //科学计数法 E+ E-
+	fmtSlash    //分数
+	fmtText     //@ 文本占位符
+	fmtDate     //日期时间标记，如yyyy、mm、AM/PM、[h]
+	fmtGeneral  //General
+	fmtThousand //千位分隔符
+	fmtScale    //数字后的逗号，每个除以1000
+)
+
+type fmtToken struct {
+	kind  int
+	text  string //fmtLiteral 为文本，fmtDigit 为占位符，fmtDate 为小写的日期标记，fmtExp 为符号
+	group int    //数字占位符所属的组
+}
+
+//数字占位符的分组
+const (
+	groupInt = iota
+	groupFrac
+	groupExp
+	groupNum //分子
+	groupDen //分母
+)
+
+//数字格式中以分号分隔的一节
+type fmtSection struct {
+	tokens   []fmtToken
+	cond     string //条件运算符，如>、<=，为空时没有条件
+	condVal  float64
+	isDate   bool
+	isText   bool
+	general  bool
+	percent  int
+	scale    int
+	exp      bool
+	fraction bool
+	denFixed int  //分数的固定分母，如 ?/16
+	hour12   bool //包含AM/PM
+	subsec   int  //秒的小数位数
+	thousand bool
+	digits   [5][]byte //每组的占位符
+}
+
+type numFormat struct {
+	sections []*fmtSection
+}
+
+var numFormatCache sync.Map
+
+func parseNumFormatCached(format string) *numFormat {
+	if f, ok := numFormatCache.Load(format); ok {
+		return f.(*numFormat)
+	}
+	f := parseNumFormat(format)
+	numFormatCache.Store(format, f)
+	return f
+}
+
+//按Excel 数字格式渲染单元格的值，即用户在Excel 中看到的文本
+//value:单元格原始文本,format:数字格式代码,date1904:日期是否从1904-01-01 开始计算
+//value 不是数值时只应用格式中的文本节(@)
+func FormatValue(value, format string, date1904 bool) string {
+	f := parseNumFormatCached(format)
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return f.formatText(value)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) { //Excel 中不会出现，原样返回
+		return value
+	}
+	return f.formatNumber(v, date1904)
+}
+
+//按单元格的样式渲染文本，布尔值为TRUE/FALSE
+func (this *reader) FormatCell(c Cell) string {
+	switch c.Kind {
+	case KindEmpty, KindError:
+		return c.Raw
+	case KindBool:
+		if c.Raw == "1" || strings.EqualFold(c.Raw, "true") {
+			return "TRUE"
+		}
+		return "FALSE"
+	case KindString:
+		return parseNumFormatCached(this.styles.format(c.style)).formatText(c.Raw)
+	}
+	return FormatValue(c.Raw, this.styles.format(c.style), this.workbook.date1904)
+}
+
+//formatted 为true 时FetchRow 返回按数字格式渲染后的文本，而不是原始文本，需在Open 之前调用
+func (this *reader) SetFormatted(formatted bool) {
+	this.formatted = formatted
+}
+
+func parseNumFormat(format string) *numFormat {
+	this := &numFormat{}
+	if strings.TrimSpace(format) == "" {
+		format = "General"
+	}
+	for _, s := range splitSections(format) {
+		this.sections = append(this.sections, parseSection(s))
+	}
+	if len(this.sections) == 0 {
+		this.sections = append(this.sections, parseSection("General"))
+	}
+	return this
+}
+
+//按分号拆分，忽略引号、转义字符及方括号中的分号
+func splitSections(format string) []string {
+	var sections []string
+	start := 0
+	for i := 0; i < len(format); i++ {
+		switch format[i] {
+		case '"':
+			if j := strings.IndexByte(format[i+1:], '"'); j > -1 {
+				i += j + 1
+			} else {
+				i = len(format)
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			if j := strings.IndexByte(format[i:], ']'); j > -1 {
+				i += j
+			}
+		case ';':
+			sections = append(sections, format[start:i])
+			start = i + 1
+		}
+	}
+	return append(sections, format[start:])
+}
+
+func parseSection(s string) *fmtSection {
+	this := &fmtSection{}
+	tokens := this.tokenize(s)
+	//识别分钟与月份，m 前面是小时或后面是秒时为分钟
+	for i, t := range tokens {
+		if t.kind != fmtDate || (t.text != "m" && t.text != "mm") {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if tokens[j].kind == fmtDate {
+				if p := tokens[j].text; p[0] == 'h' || p == "[h]" || p == "[hh]" {
+					tokens[i].text = "min" + t.text
+				}
+				break
+			}
+		}
+		for j := i + 1; j < len(tokens) && tokens[i].text == t.text; j++ {
+			if tokens[j].kind == fmtDate {
+				if n := tokens[j].text; n[0] == 's' || n == "[s]" || n == "[ss]" {
+					tokens[i].text = "min" + t.text
+				}
+				break
+			}
+		}
+	}
+	if this.isDate {
+		//日期格式中秒后面的.0 为秒的小数
+		for i := 0; i < len(tokens); i++ {
+			if tokens[i].kind != fmtPoint {
+				continue
+			}
+			j := i + 1
+			for j < len(tokens) && tokens[j].kind == fmtDigit && tokens[j].text == "0" {
+				j++
+			}
+			if j > i+1 {
+				this.subsec = j - i - 1
+				tokens = append(tokens[:i+1], tokens[j:]...)
+				tokens[i] = fmtToken{kind: fmtDate, text: "." + strings.Repeat("0", this.subsec)}
+			} else {
+				tokens[i] = fmtToken{kind: fmtLiteral, text: "."}
+			}
+		}
+		for i := range tokens {
+			switch tokens[i].kind {
+			case fmtDigit, fmtComma, fmtSlash, fmtPercent:
+				tokens[i] = fmtToken{kind: fmtLiteral, text: tokens[i].text}
+			}
+		}
+		this.tokens = tokens
+		return this
+	}
+	this.tokens = tokens
+	this.groupDigits()
+	return this
+}
+
+func (this *fmtSection) tokenize(s string) []fmtToken {
+	var tokens []fmtToken
+	literal := func(text string) {
+		tokens = append(tokens, fmtToken{kind: fmtLiteral, text: text})
+	}
+	date := func(text string) {
+		this.isDate = true
+		tokens = append(tokens, fmtToken{kind: fmtDate, text: text})
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			j := strings.IndexByte(s[i+1:], '"')
+			if j < 0 {
+				j = len(s) - i - 1
+			}
+			literal(s[i+1 : i+1+j])
+			i += j + 1
+		case c == '\\':
+			if i+1 < len(s) {
+				_, size := utf8.DecodeRuneInString(s[i+1:])
+				literal(s[i+1 : i+1+size])
+				i += size
+			}
+		case c == '_':
+			//按后一个字符的宽度留空
+			literal(" ")
+			if i+1 < len(s) {
+				_, size := utf8.DecodeRuneInString(s[i+1:])
+				i += size
+			}
+		case c == '*':
+			//重复填充至列宽，忽略
+			if i+1 < len(s) {
+				_, size := utf8.DecodeRuneInString(s[i+1:])
+				i += size
+			}
+		case c == '[':
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				i = len(s)
+				break
+			}
+			this.bracket(s[i+1:i+j], literal, date)
+			i += j
+		case c == '0' || c == '#' || c == '?':
+			tokens = append(tokens, fmtToken{kind: fmtDigit, text: string(c)})
+		case c == '.':
+			tokens = append(tokens, fmtToken{kind: fmtPoint, text: "."})
+		case c == ',':
+			tokens = append(tokens, fmtToken{kind: fmtComma, text: ","})
+		case c == '%':
+			this.percent++
+			tokens = append(tokens, fmtToken{kind: fmtPercent, text: "%"})
+		case c == '/':
+			tokens = append(tokens, fmtToken{kind: fmtSlash, text: "/"})
+		case c == '@':
+			this.isText = true
+			tokens = append(tokens, fmtToken{kind: fmtText})
+		case (c == 'E' || c == 'e') && i+1 < len(s) && (s[i+1] == '+' || s[i+1] == '-'):
+			this.exp = true
+			tokens = append(tokens, fmtToken{kind: fmtExp, text: s[i+1 : i+2]})
+			i++
+		case generalLen(s[i:]) > 0:
+			//General 及G/通用格式 等本地化写法
+			this.general = true
+			tokens = append(tokens, fmtToken{kind: fmtGeneral})
+			i += generalLen(s[i:]) - 1
+		case (c|0x20 == 'g' || c|0x20 == 'e') && (i == 0 || !isASCIILetter(s[i-1])) && eraLen(s[i:]) > 0:
+			//日本年号g、gg、ggg 及年号年份e、ee
+			n := eraLen(s[i:])
+			g := strings.IndexFunc(strings.ToLower(s[i:i+n]), func(r rune) bool { return r == 'e' })
+			if g < 0 {
+				g = n
+			}
+			if g > 0 {
+				date(strings.ToLower(s[i : i+g]))
+			}
+			if n > g {
+				date(strings.ToLower(s[i+g : i+n]))
+			}
+			i += n - 1
+		case strings.HasPrefix(strings.ToUpper(s[i:]), "AM/PM"):
+			this.hour12 = true
+			date("am/pm")
+			i += len("AM/PM") - 1
+		case strings.HasPrefix(strings.ToUpper(s[i:]), "A/P"):
+			this.hour12 = true
+			date("a/p")
+			i += len("A/P") - 1
+		case strings.HasPrefix(s[i:], "上午/下午"):
+			this.hour12 = true
+			date("上午/下午")
+			i += len("上午/下午") - 1
+		case strings.ContainsRune("yYmMdDhHsSaA", rune(c)):
+			lc := c | 0x20
+			j := i + 1
+			for j < len(s) && s[j]|0x20 == lc {
+				j++
+			}
+			text := strings.ToLower(s[i:j])
+			if lc == 'a' && j-i < 3 {
+				//aaa、aaaa 为中文星期，其他情况为普通文本
+				literal(s[i:j])
+			} else {
+				date(text)
+			}
+			i = j - 1
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			literal(s[i : i+size])
+			i += size - 1
+		}
+	}
+	return tokens
+}
+
+//方括号：颜色、条件、经过时间、货币符号及区域
+func (this *fmtSection) bracket(b string, literal, date func(string)) {
+	lb := strings.ToLower(b)
+	switch {
+	case lb == "":
+	case strings.Trim(lb, "h") == "" || strings.Trim(lb, "m") == "" || strings.Trim(lb, "s") == "":
+		date("[" + lb + "]")
+	case b[0] == '$':
+		//[$¥-804] 输出货币符号，[$-804] 只是区域
+		if j := strings.IndexByte(b, '-'); j > -1 {
+			literal(b[1:j])
+		} else {
+			literal(b[1:])
+		}
+	case b[0] == '<' || b[0] == '>' || b[0] == '=':
+		op := b[:1]
+		if len(b) > 1 && (b[1] == '=' || b[1] == '>') {
+			op = b[:2]
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(b[len(op):]), 64); err == nil {
+			this.cond, this.condVal = op, v
+		}
+	}
+	//其余为颜色、[DBNum1] 等，忽略
+}
+
+//确定逗号的含义并将数字占位符分组
+func (this *fmtSection) groupDigits() {
+	tokens := this.tokens
+	slash := -1
+	for i, t := range tokens {
+		if t.kind == fmtSlash {
+			slash = i
+			break
+		}
+	}
+	if slash > -1 {
+		this.groupFraction(slash)
+		return
+	}
+	//.00 这样没有整数占位符的格式，整数部分仍需输出
+	for i, t := range tokens {
+		if t.kind == fmtDigit {
+			break
+		}
+		if t.kind == fmtPoint {
+			tokens = append(tokens[:i], append([]fmtToken{{kind: fmtDigit, text: "#"}}, tokens[i:]...)...)
+			this.tokens = tokens
+			break
+		}
+	}
+	group := groupInt
+	for i := range tokens {
+		t := &tokens[i]
+		switch t.kind {
+		case fmtPoint:
+			if group == groupInt {
+				group = groupFrac
+			} else {
+				t.kind = fmtLiteral
+			}
+		case fmtExp:
+			group = groupExp
+		case fmtDigit:
+			t.group = group
+			this.digits[group] = append(this.digits[group], t.text[0])
+		case fmtComma:
+			prevDigit, nextDigit := false, false
+			for j := i - 1; j >= 0; j-- {
+				if tokens[j].kind == fmtDigit {
+					prevDigit = true
+					break
+				} else if k := tokens[j].kind; k != fmtComma && k != fmtScale && k != fmtThousand {
+					break
+				}
+			}
+			for j := i + 1; j < len(tokens); j++ {
+				if tokens[j].kind == fmtDigit {
+					nextDigit = true
+					break
+				} else if tokens[j].kind != fmtComma {
+					break
+				}
+			}
+			switch {
+			case prevDigit && nextDigit && group == groupInt:
+				t.kind = fmtThousand
+				this.thousand = true
+			case prevDigit && !nextDigit && group != groupExp:
+				t.kind = fmtScale
+				this.scale++
+			default:
+				t.kind = fmtLiteral
+			}
+		}
+	}
+}
+
+//分数格式：分子为斜杠前紧邻的一组占位符，之前的占位符为整数部分
+func (this *fmtSection) groupFraction(slash int) {
+	tokens := this.tokens
+	this.fraction = true
+	i := slash - 1
+	for i >= 0 && tokens[i].kind == fmtDigit {
+		tokens[i].group = groupNum
+		i--
+	}
+	for ; i >= 0; i-- {
+		if tokens[i].kind == fmtDigit {
+			tokens[i].group = groupInt
+		} else if tokens[i].kind == fmtComma {
+			tokens[i].kind = fmtLiteral
+		}
+	}
+	//分母为占位符或固定的数字
+	fixed := ""
+	for j := slash + 1; j < len(tokens); j++ {
+		t := &tokens[j]
+		if t.kind == fmtDigit || (t.kind == fmtLiteral && len(t.text) == 1 && t.text[0] >= '1' && t.text[0] <= '9') {
+			fixed += t.text
+			t.kind = fmtDigit
+			t.group = groupDen
+		} else {
+			if t.kind == fmtComma || t.kind == fmtPoint {
+				t.kind = fmtLiteral
+			}
+			break
+		}
+	}
+	if fixed != "" && strings.Trim(fixed, "0#?") != "" {
+		this.denFixed, _ = strconv.Atoi(strings.NewReplacer("#", "0", "?", "0").Replace(fixed))
+	}
+	for _, t := range tokens {
+		if t.kind == fmtDigit {
+			this.digits[t.group] = append(this.digits[t.group], t.text[0])
+		}
+	}
+}
+
+//数值使用的节，最后一节包含@ 时为文本节，不参与数值的选择
+func (this *numFormat) numericSections() []*fmtSection {
+	secs := this.sections
+	if n := len(secs); n > 1 && secs[n-1].isText {
+		secs = secs[:n-1]
+	}
+	if len(secs) > 3 {
+		secs = secs[:3]
+	}
+	return secs
+}
+
+//选择数值所使用的节，返回节及是否去掉负号
+func (this *numFormat) section(v float64) (*fmtSection, bool) {
+	secs := this.numericSections()
+	n := len(secs)
+	if secs[0].cond != "" || (n > 1 && secs[1].cond != "") {
+		for i := 0; i < n && i < 2; i++ {
+			if secs[i].cond != "" && secs[i].match(v) {
+				//第二节的条件针对负数时不显示负号
+				return secs[i], i == 1 && secs[i].cond[0] == '<' && secs[i].condVal <= 0
+			}
+		}
+		if n > 2 || (n == 2 && secs[1].cond == "") {
+			return secs[n-1], false
+		}
+		return secs[0], false
+	}
+	switch {
+	case n == 1:
+		return secs[0], false
+	case v > 0 || (v == 0 && n == 2):
+		return secs[0], false
+	case v < 0:
+		return secs[1], true
+	case n == 2: //NaN
+		return secs[0], false
+	}
+	return secs[2], false
+}
+
+func (this *fmtSection) match(v float64) bool {
+	switch this.cond {
+	case "<":
+		return v < this.condVal
+	case "<=":
+		return v <= this.condVal
+	case ">":
+		return v > this.condVal
+	case ">=":
+		return v >= this.condVal
+	case "=":
+		return v == this.condVal
+	case "<>":
+		return v != this.condVal
+	}
+	return false
+}
+
+func (this *numFormat) formatText(value string) string {
+	n := len(this.sections)
+	sec := this.sections[n-1]
+	if n < 4 && !sec.isText {
+		return value
+	}
+	var sb strings.Builder
+	for _, t := range sec.tokens {
+		switch t.kind {
+		case fmtText:
+			sb.WriteString(value)
+		case fmtLiteral:
+			sb.WriteString(t.text)
+		}
+	}
+	return sb.String()
+}
+
+func (this *numFormat) formatNumber(v float64, date1904 bool) string {
+	sec, abs := this.section(v)
+	if abs {
+		v = math.Abs(v)
+	}
+	switch {
+	case sec.isDate:
+		if v < 0 {
+			return formatGeneral(v)
+		}
+		return sec.formatDate(v, date1904)
+	case sec.general && len(sec.digits[groupInt])+len(sec.digits[groupFrac]) == 0:
+		var sb strings.Builder
+		for _, t := range sec.tokens {
+			switch t.kind {
+			case fmtGeneral:
+				sb.WriteString(formatGeneral(v))
+			case fmtLiteral:
+				sb.WriteString(t.text)
+			}
+		}
+		return sb.String()
+	case sec.isText && len(sec.digits[groupInt])+len(sec.digits[groupFrac]) == 0:
+		return this.formatText(formatGeneral(v))
+	}
+	return sec.formatDigits(v)
+}
+
+//General 格式：最多显示11 个字符，过大或过小的数使用科学计数法
+func formatGeneral(v float64) string {
+	if v == 0 {
+		return "0"
+	}
+	a := math.Abs(v)
+	if a >= 1e11 || a < 1e-9 {
+		return parseNumFormatCached("0.#####E+00").sections[0].formatDigits(v)
+	}
+	ip, _ := decimalDigits(a, 0)
+	decimals := 10 - len(ip)
+	if ip == "" {
+		decimals = 9
+	}
+	ip, fp := decimalDigits(a, decimals)
+	fp = strings.TrimRight(fp, "0")
+	if ip == "" {
+		ip = "0"
+	}
+	s := ip
+	if fp != "" {
+		s += "." + fp
+	}
+	if v < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+//按15 位有效数字四舍五入到decimals 位小数，返回整数部分(0 时为空)与小数部分
+func decimalDigits(v float64, decimals int) (string, string) {
+	e := strconv.FormatFloat(v, 'e', 14, 64)
+	mant, exp := e, 0
+	if i := strings.IndexByte(e, 'e'); i > -1 {
+		mant = e[:i]
+		exp, _ = strconv.Atoi(e[i+1:])
+	}
+	digits := strings.Replace(mant, ".", "", 1)
+	var ip, fp string
+	if n := exp + 1; n <= 0 {
+		fp = strings.Repeat("0", -n) + digits
+	} else if n >= len(digits) {
+		ip = digits + strings.Repeat("0", n-len(digits))
+	} else {
+		ip, fp = digits[:n], digits[n:]
+	}
+	if len(fp) < decimals {
+		fp += strings.Repeat("0", decimals-len(fp))
+	}
+	//四舍五入
+	all := []byte(ip + fp[:decimals])
+	if len(fp) > decimals && fp[decimals] >= '5' {
+		i := len(all) - 1
+		for ; i >= 0; i-- {
+			if all[i] == '9' {
+				all[i] = '0'
+			} else {
+				all[i]++
+				break
+			}
+		}
+		if i < 0 {
+			all = append([]byte{'1'}, all...)
+		}
+	}
+	ip = strings.TrimLeft(string(all[:len(all)-decimals]), "0")
+	return ip, string(all[len(all)-decimals:])
+}
+
+func (this *fmtSection) formatDigits(v float64) string {
+	neg := v < 0
+	v = math.Abs(v)
+	v *= math.Pow(100, float64(this.percent))
+	v /= math.Pow(1000, float64(this.scale))
+
+	var out [5][]string
+	slash := "/"
+	switch {
+	case this.fraction:
+		neg = neg && !this.fractionZero(v)
+		var blank bool
+		if out, blank = this.fractionDigits(v); blank {
+			slash = " "
+		}
+	case this.exp:
+		out = this.expDigits(v)
+	default:
+		ip, fp := decimalDigits(v, len(this.digits[groupFrac]))
+		if neg && strings.Trim(ip+fp, "0") == "" {
+			neg = false
+		}
+		out[groupInt] = fillRight(this.digits[groupInt], ip, this.thousand)
+		out[groupFrac] = fillLeft(this.digits[groupFrac], fp)
+	}
+	var sb strings.Builder
+	if neg {
+		sb.WriteByte('-')
+	}
+	var index [5]int
+	for _, t := range this.tokens {
+		switch t.kind {
+		case fmtLiteral:
+			sb.WriteString(t.text)
+		case fmtPoint, fmtPercent:
+			sb.WriteString(t.text)
+		case fmtSlash:
+			sb.WriteString(slash)
+		case fmtDigit:
+			sb.WriteString(out[t.group][index[t.group]])
+			index[t.group]++
+		case fmtExp:
+			sb.WriteByte('E')
+			sb.WriteString(out[groupExp][len(out[groupExp])-1])
+		case fmtGeneral:
+			sb.WriteString(formatGeneral(v))
+		}
+	}
+	return sb.String()
+}
+
+//整数部分：从右向左填充，多出的数字都放在最左侧的占位符
+func fillRight(holders []byte, digits string, thousand bool) []string {
+	out := make([]string, len(holders))
+	k := 0
+	put := func(i int, c byte) {
+		if thousand && k > 0 && k%3 == 0 {
+			out[i] = string(c) + "," + out[i]
+		} else {
+			out[i] = string(c) + out[i]
+		}
+		k++
+	}
+	d := len(digits) - 1
+	for i := len(holders) - 1; i >= 0; i-- {
+		if d >= 0 {
+			put(i, digits[d])
+			d--
+			if i == 0 {
+				for ; d >= 0; d-- {
+					put(i, digits[d])
+				}
+			}
+			continue
+		}
+		switch holders[i] {
+		case '0':
+			put(i, '0')
+		case '?':
+			out[i] = " "
+		}
+	}
+	return out
+}
+
+//小数部分：从左向右填充，末尾的0 按占位符省略或替换为空格
+func fillLeft(holders []byte, digits string) []string {
+	out := make([]string, len(holders))
+	trailing := true
+	for i := len(holders) - 1; i >= 0; i-- {
+		c := digits[i]
+		if trailing && c == '0' && holders[i] != '0' {
+			if holders[i] == '?' {
+				out[i] = " "
+			}
+			continue
+		}
+		trailing = false
+		out[i] = string(c)
+	}
+	return out
+}
+
+//科学计数法：以#开头且整数位多于一位时指数为整数位数的倍数(工程计数法)
+func (this *fmtSection) expDigits(v float64) [5][]string {
+	var out [5][]string
+	intN := len(this.digits[groupInt])
+	if intN == 0 {
+		intN = 1
+	}
+	decimals := len(this.digits[groupFrac])
+	exp := 0
+	if v != 0 {
+		exp = int(math.Floor(math.Log10(v)))
+		if intN > 1 && this.digits[groupInt][0] == '#' {
+			exp = int(math.Floor(float64(exp)/float64(intN))) * intN
+		} else {
+			exp -= intN - 1
+		}
+	}
+	ip, fp := decimalDigits(v/math.Pow(10, float64(exp)), decimals)
+	//四舍五入后进位，如9.99E+00 变为10.0
+	if limit := intN; len(ip) > limit && !(this.digits[groupInt][0] == '#' && intN > 1) {
+		exp++
+		ip, fp = decimalDigits(v/math.Pow(10, float64(exp)), decimals)
+	}
+	out[groupInt] = fillRight(this.digits[groupInt], ip, false)
+	out[groupFrac] = fillLeft(this.digits[groupFrac], fp)
+	sign := ""
+	for _, t := range this.tokens {
+		if t.kind == fmtExp {
+			if exp < 0 {
+				sign = "-"
+			} else if t.text == "+" {
+				sign = "+"
+			}
+		}
+	}
+	e := strconv.Itoa(int(math.Abs(float64(exp))))
+	if e == "0" {
+		e = ""
+	}
+	out[groupExp] = fillRight(this.digits[groupExp], e, false)
+	//最后一个元素用于E 后面的符号
+	out[groupExp] = append(out[groupExp], sign)
+	return out
+}
+
+//分数的整数部分、分子与分母
+func (this *fmtSection) fraction3(v float64) (ip, num, den int) {
+	f := v
+	if len(this.digits[groupInt]) > 0 {
+		ip = int(v)
+		f = v - float64(ip)
+	}
+	if this.denFixed > 0 {
+		den = this.denFixed
+		num = int(math.Round(f * float64(den)))
+	} else {
+		maxDen := int(math.Pow(10, float64(len(this.digits[groupDen])))) - 1
+		if maxDen < 1 {
+			maxDen = 1
+		}
+		num, den = approximate(f, maxDen)
+	}
+	if len(this.digits[groupInt]) > 0 && num == den {
+		ip++
+		num = 0
+	}
+	return
+}
+
+func (this *fmtSection) fractionZero(v float64) bool {
+	ip, num, _ := this.fraction3(v)
+	return ip == 0 && num == 0
+}
+
+//分数部分为0 时blank 为true，分数位置留空
+func (this *fmtSection) fractionDigits(v float64) (out [5][]string, blank bool) {
+	ip, num, den := this.fraction3(v)
+	is := ""
+	if ip > 0 {
+		is = strconv.Itoa(ip)
+	}
+	if num == 0 && len(this.digits[groupInt]) > 0 {
+		//没有分数部分时，分数位置留空
+		if ip == 0 {
+			is = "0"
+		}
+		out[groupInt] = fillRight(this.digits[groupInt], is, false)
+		for _, g := range []int{groupNum, groupDen} {
+			for range this.digits[g] {
+				out[g] = append(out[g], " ")
+			}
+		}
+		return out, true
+	}
+	out[groupInt] = fillRight(this.digits[groupInt], is, false)
+	out[groupNum] = fillRight(this.digits[groupNum], strconv.Itoa(num), false)
+	//分母靠左对齐
+	ds := strconv.Itoa(den)
+	holders := this.digits[groupDen]
+	out[groupDen] = make([]string, len(holders))
+	for i := range holders {
+		switch {
+		case this.denFixed > 0:
+			if i == 0 {
+				out[groupDen][i] = ds
+			}
+		case i < len(ds):
+			out[groupDen][i] = string(ds[i])
+			if i == len(holders)-1 {
+				out[groupDen][i] = ds[i:]
+			}
+		case holders[i] == '?':
+			out[groupDen][i] = " "
+		}
+	}
+	return out, false
+}
+
+//分母不超过maxDen 的最佳有理数近似
+func approximate(f float64, maxDen int) (int, int) {
+	if f == 0 {
+		return 0, 1
+	}
+	//连分数展开
+	p0, q0, p1, q1 := 0, 1, 1, 0
+	x := f
+	for i := 0; i < 64; i++ {
+		a := int(math.Floor(x))
+		p2, q2 := a*p1+p0, a*q1+q0
+		if q2 > maxDen {
+			//比较最后一个半收敛值
+			k := (maxDen - q0) / q1
+			pk, qk := k*p1+p0, k*q1+q0
+			if math.Abs(f-float64(pk)/float64(qk)) < math.Abs(f-float64(p1)/float64(q1)) {
+				return pk, qk
+			}
+			return p1, q1
+		}
+		p0, q0, p1, q1 = p1, q1, p2, q2
+		if x-float64(a) < 1e-12 {
+			break
+		}
+		x = 1 / (x - float64(a))
+	}
+	return p1, q1
+}
+
+var (
+	monthNames = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	weekNames  = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	weekNamesC = []string{"日", "一", "二", "三", "四", "五", "六"}
+
+	//日本年号，按开始日期从新到旧排列
+	japaneseEras = []struct {
+		start      time.Time
+		name, abbr string
+	}{
+		{time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC), "令和", "R"},
+		{time.Date(1989, 1, 8, 0, 0, 0, 0, time.UTC), "平成", "H"},
+		{time.Date(1926, 12, 25, 0, 0, 0, 0, time.UTC), "昭和", "S"},
+		{time.Date(1912, 7, 30, 0, 0, 0, 0, time.UTC), "大正", "T"},
+		{time.Date(1868, 9, 8, 0, 0, 0, 0, time.UTC), "明治", "M"},
+	}
+)
+
+//t 所在的日本年号及年号年份，明治之前年号为空、年份为公历年份
+func japaneseEra(t time.Time) (name, abbr string, year int) {
+	for _, era := range japaneseEras {
+		if !t.Before(era.start) {
+			return era.name, era.abbr, t.Year() - era.start.Year() + 1
+		}
+	}
+	return "", "", t.Year()
+}
+
+func (this *fmtSection) formatDate(v float64, date1904 bool) string {
+	//按显示的精度四舍五入，避免59.9999 秒显示为59 秒
+	unit := 86400 * math.Pow(10, float64(this.subsec))
+	v = math.Round(v*unit) / unit
+	t := GetExcelTime(v, date1904)
+	t = t.Round(time.Duration(math.Pow(10, float64(9-this.subsec))))
+	var sb strings.Builder
+	pad := func(n int, width int) {
+		s := strconv.Itoa(n)
+		for i := len(s); i < width; i++ {
+			sb.WriteByte('0')
+		}
+		sb.WriteString(s)
+	}
+	for _, tk := range this.tokens {
+		if tk.kind == fmtLiteral {
+			sb.WriteString(tk.text)
+			continue
+		}
+		if tk.kind != fmtDate {
+			continue
+		}
+		switch s := tk.text; {
+		case s == "y" || s == "yy":
+			pad(t.Year()%100, 2)
+		case s[0] == 'y':
+			pad(t.Year(), 4)
+		case s == "m":
+			pad(int(t.Month()), 1)
+		case s == "mm":
+			pad(int(t.Month()), 2)
+		case s == "mmm":
+			sb.WriteString(monthNames[t.Month()-1][:3])
+		case s == "mmmmm":
+			sb.WriteString(monthNames[t.Month()-1][:1])
+		case s[0] == 'm' && !strings.HasPrefix(s, "min"):
+			sb.WriteString(monthNames[t.Month()-1])
+		case s == "d":
+			pad(t.Day(), 1)
+		case s == "dd":
+			pad(t.Day(), 2)
+		case s == "ddd":
+			sb.WriteString(weekNames[t.Weekday()][:3])
+		case s[0] == 'd':
+			sb.WriteString(weekNames[t.Weekday()])
+		case s[0] == 'g':
+			//g:R，gg:令，ggg:令和
+			name, abbr, _ := japaneseEra(t)
+			switch len(s) {
+			case 1:
+				sb.WriteString(abbr)
+			case 2:
+				if r, _ := utf8.DecodeRuneInString(name); name != "" {
+					sb.WriteRune(r)
+				}
+			default:
+				sb.WriteString(name)
+			}
+		case s[0] == 'e':
+			_, _, year := japaneseEra(t)
+			pad(year, len(s))
+		case s == "aaa":
+			sb.WriteString(weekNamesC[t.Weekday()])
+		case s[0] == 'a' && s != "am/pm" && s != "a/p":
+			sb.WriteString("星期" + weekNamesC[t.Weekday()])
+		case s[0] == 'h':
+			h := t.Hour()
+			if this.hour12 {
+				h = h % 12
+				if h == 0 {
+					h = 12
+				}
+			}
+			pad(h, len(s))
+		case s == "minm" || s == "minmm":
+			pad(t.Minute(), len(s)-3)
+		case s[0] == 's':
+			pad(t.Second(), len(s))
+		case s[0] == '.':
+			sb.WriteByte('.')
+			pad(t.Nanosecond()/int(math.Pow(10, float64(9-this.subsec))), this.subsec)
+		case s == "am/pm":
+			if t.Hour() < 12 {
+				sb.WriteString("AM")
+			} else {
+				sb.WriteString("PM")
+			}
+		case s == "a/p":
+			if t.Hour() < 12 {
+				sb.WriteString("A")
+			} else {
+				sb.WriteString("P")
+			}
+		case s == "上午/下午":
+			if t.Hour() < 12 {
+				sb.WriteString("上午")
+			} else {
+				sb.WriteString("下午")
+			}
+		case s[0] == '[':
+			//经过的时间
+			var n float64
+			switch s[1] {
+			case 'h':
+				n = math.Floor(v * 24)
+			case 'm':
+				n = math.Floor(v * 1440)
+			default:
+				n = math.Floor(v * 86400)
+			}
+			pad(int(n), len(s)-2)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+package xlsx_reader
+
+import (
+	"testing"
+)
+
+func TestFormatValue(t *testing.T) {
+	cases := []struct {
+		value, format, want string
+	}{
+		{"0.30000000000000004", "General", "0.3"},
+		{"45123", "General", "45123"},
+		{"1.2E-3", "General", "0.0012"},
+		{"123456789012", "General", "1.23457E+11"},
+		{"0.333333333333333", "General", "0.333333333"},
+		{"-5", "General", "-5"},
+		{"abc", "General", "abc"},
+		{"123.5", "G/通用格式", "123.5"},
+		{"-2", `G/通用格式"元"`, "-2元"},
+		{"123.5", "", "123.5"},
+		{"45488", "ge.m.d", "R6.7.15"},
+		{"45488", `[$-411]ggge"年"m"月"d"日"`, "令和6年7月15日"},
+		{"32516", "gg ee/mm/dd", "平 01/01/08"},
+		{"45488", "[$-411]e", "6"},
+		{"NaN", "0.00;-0.00", "NaN"},
+		{"NaN", "0.00_);(0.00)", "NaN"},
+		{"+Inf", "0.00E+00", "+Inf"},
+		{"-Inf", "#,##0.00;(#,##0.00);0", "-Inf"},
+		{"Inf", "yyyy-mm-dd", "Inf"},
+		{"1234.5", "0", "1235"},
+		{"2.675", "0.00", "2.68"},
+		{"1234567.891", "#,##0.00", "1,234,567.89"},
+		{"-1234567.891", "#,##0.00", "-1,234,567.89"},
+		{"0.5", "#,##0", "1"},
+		{"0", "#,##0", "0"},
+		{"0.5", "#.##", ".5"},
+		{"1", "#.##", "1."},
+		{"12.5", ".00", "12.50"},
+		{"1.5", "0.0#", "1.5"},
+		{"1.5", "0.???", "1.5  "},
+		{"0.1234", "0%", "12%"},
+		{"0.1234", "0.00%", "12.34%"},
+		{"1234567", "#,##0,", "1,235"},
+		{"1234567", `0.0,,"M"`, "1.2M"},
+		{"0.0012", "0.00E+00", "1.20E-03"},
+		{"123456", "0.00E+00", "1.23E+05"},
+		{"12345", "##0.0E+0", "12.3E+3"},
+		{"0", "0.00E+00", "0.00E+00"},
+		{"1.25", "# ?/?", "1 1/4"},
+		{"0.3333", "# ??/??", "  1/3 "},
+		{"2", "# ?/?", "2    "},
+		{"0.5", "?/4", "2/4"},
+		{"3.1416", "# ??/100", "3 14/100"},
+		{"1234.5", `"¥"#,##0.00`, "¥1,234.50"},
+		{"-1234.5", `"¥"#,##0.00;"¥"\-#,##0.00`, "¥-1,234.50"},
+		{"-1234.5", `#,##0.00;[Red](#,##0.00)`, "(1,234.50)"},
+		{"0", `#,##0.00;(#,##0.00);"零"`, "零"},
+		{"-5", `[$¥-804]#,##0`, "-¥5"},
+		{"150", `[>100]"大";[<=100]"小"`, "大"},
+		{"50", `[>100]"大";[<=100]"小"`, "小"},
+		{"-150", `[>=100]0;[<=-100]"-"0;0.0`, "-150"},
+		{"50", `[>=100]0;[<=-100]"-"0;0.0`, "50.0"},
+		{"1234.5", `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`, " 1,234.50 "},
+		{"0", `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`, " -   "},
+		{"文本", `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`, " 文本 "},
+		{"文本", `0;"值:"@`, "值:文本"},
+		{"-3", `0;@`, "-3"},
+		{"13800138000", "000-0000-0000", "138-0013-8000"},
+		{"45352", "yyyy-mm-dd", "2024-03-01"},
+		{"45352", "yyyy/m/d", "2024/3/1"},
+		{"45352", `yyyy"年"m"月"d"日"`, "2024年3月1日"},
+		{"45352.75", "yyyy-mm-dd hh:mm:ss", "2024-03-01 18:00:00"},
+		{"45352.75", "h:mm AM/PM", "6:00 PM"},
+		{"45352.25", `上午/下午h"时"mm"分"`, "上午6时00分"},
+		{"45352", "d-mmm-yy", "1-Mar-24"},
+		{"45352", "dddd, mmmm d", "Friday, March 1"},
+		{"45352", "aaaa", "星期五"},
+		{"45352", "mmmmm", "M"},
+		{"1.5", "[h]:mm:ss", "36:00:00"},
+		{"0.000011574", "hh:mm:ss.000", "00:00:01.000"},
+		{"0.99999999", "hh:mm:ss", "00:00:00"},
+		{"0.5", "mm:ss", "00:00"},
+		{"0.0104166667", "[mm]:ss", "15:00"},
+		{"45352", "[$-804]yyyy-mm-dd;@", "2024-03-01"},
+	}
+	for _, c := range cases {
+		if got := FormatValue(c.value, c.format, false); got != c.want {
+			t.Errorf("FormatValue(%q, %q)=%q want %q", c.value, c.format, got, c.want)
+		}
+	}
+}
+
+func TestReader_SetFormatted(t *testing.T) {
+	f := dateFixture
+	f.sheets = []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1">` +
+		`<c r="A1" s="1"><v>45352</v></c>` +
+		`<c r="B1" s="5"><v>1234.5</v></c>` +
+		`<c r="C1" t="b"><v>0</v></c>` +
+		`<c r="D1"><v>0.30000000000000004</v></c>` +
+		`<c r="E1" s="6"><v>123</v></c>` +
+		`</row>`}}
+	//中文Excel、WPS 默认的G/通用格式
+	f.files = map[string]string{"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="2"><numFmt numFmtId="177" formatCode="#,##0.00_ "/><numFmt numFmtId="178" formatCode="G/通用格式"/></numFmts>` +
+		`<cellXfs count="7"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="0"/><xf numFmtId="0"/><xf numFmtId="0"/><xf numFmtId="177"/><xf numFmtId="178"/></cellXfs>` +
+		`</styleSheet>`}
+	r := ReaderFromBytes(f.bytes(t), "", false)
+	r.SetFormatted(true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	rows := fetchAll(t, r)
+	want := []string{"2024/3/1", "1,234.50 ", "FALSE", "0.3", "123"}
+	for i, w := range want {
+		if rows[0][i] != w {
+			t.Errorf("col %d=%q want %q", i, rows[0][i], w)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package xlsx_reader
 import "encoding/xml"
 
 type xlsxWorkbook struct {
-	XMLName xml.Name   `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main workbook"`
-	Sheets  xlsxSheets `xml:"sheets"`
+	XMLName      xml.Name         `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main workbook"`
+	WorkbookPr   xlsxWorkbookPr   `xml:"workbookPr"`
+	Sheets       xlsxSheets       `xml:"sheets"`
+	DefinedNames xlsxDefinedNames `xml:"definedNames"`
 }
 
 // xlsxSheets directly maps the sheets element from the namespace
@@ -19,7 +21,22 @@ type xlsxSheet struct {
 	Name    string `xml:"name,attr,omitempty"`
 	SheetID string `xml:"sheetId,attr,omitempty"`
 	ID      string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr,omitempty"`
-	//State   string `xml:"state,attr,omitempty"`
+	State   string `xml:"state,attr,omitempty"`
+}
+
+// xlsxDefinedNames directly maps the definedNames element from the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main.
+type xlsxDefinedNames struct {
+	DefinedName []xlsxDefinedName `xml:"definedName"`
+}
+
+// xlsxDefinedName directly maps the definedName element. The value is a
+// formula, a reference or a constant such as "v2".
+type xlsxDefinedName struct {
+	Name         string `xml:"name,attr"`
+	LocalSheetID *int   `xml:"localSheetId,attr"`
+	Hidden       bool   `xml:"hidden,attr,omitempty"`
+	Data         string `xml:",chardata"`
 }
 
 // xmlxWorkbookRels contains xmlxWorkbookRelations which maps sheet id and sheet XML.
@@ -48,7 +65,7 @@ type xlsxWorksheet struct {
 	SheetData xlsxSheetData `xml:"sheetData"`
 	//SheetProtection       *xlsxSheetProtection         `xml:"sheetProtection"`
 	//AutoFilter            *xlsxAutoFilter              `xml:"autoFilter"`
-	//MergeCells            *xlsxMergeCells              `xml:"mergeCells"`
+	MergeCells *xlsxMergeCells `xml:"mergeCells"`
 	//PhoneticPr            *xlsxPhoneticPr              `xml:"phoneticPr"`
 	//ConditionalFormatting []*xlsxConditionalFormatting `xml:"conditionalFormatting"`
 	//DataValidations       *xlsxDataValidations         `xml:"dataValidations,omitempty"`
@@ -92,8 +109,8 @@ type xlsxRow struct {
 
 //xlsxC directly maps the cell element.
 type xlsxC struct {
-	R string `xml:"r,attr"` // Cell ID, e.g. A1
-	//S int    `xml:"s,attr,omitempty"` // Style reference.
+	R string `xml:"r,attr"`           // Cell ID, e.g. A1
+	S int    `xml:"s,attr,omitempty"` // Style reference.
 	T string `xml:"t,attr,omitempty"` // Type.
 	//F        *xlsxF   `xml:"f,omitempty"`      // Formula
 	V string `xml:"v,omitempty"` // Value
@@ -120,3 +137,69 @@ type xlsxSST struct {
 type xlsxSI struct {
 	T string `xml:"t"`
 }
+
+// xlsxWorkbookPr directly maps the workbookPr element from the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main
+type xlsxWorkbookPr struct {
+	Date1904 bool `xml:"date1904,attr,omitempty"`
+}
+
+// xlsxStyleSheet directly maps the stylesheet element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main
+type xlsxStyleSheet struct {
+	XMLName xml.Name    `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main styleSheet"`
+	NumFmts xlsxNumFmts `xml:"numFmts"`
+	CellXfs xlsxCellXfs `xml:"cellXfs"`
+	//Fonts        *xlsxFonts        `xml:"fonts"`
+	//Fills        *xlsxFills        `xml:"fills"`
+	//Borders      *xlsxBorders      `xml:"borders"`
+	//CellStyleXfs *xlsxCellStyleXfs `xml:"cellStyleXfs"`
+}
+
+// xlsxNumFmts directly maps the numFmts element. This element defines the
+// number formats in this workbook, consisting of a sequence of numFmt element
+// (number format).
+type xlsxNumFmts struct {
+	Count  int          `xml:"count,attr,omitempty"`
+	NumFmt []xlsxNumFmt `xml:"numFmt"`
+}
+
+// xlsxNumFmt directly maps the numFmt element. This element specifies number
+// format properties which indicate how to format and render the numeric value
+// of a cell.
+type xlsxNumFmt struct {
+	NumFmtID   int    `xml:"numFmtId,attr"`
+	FormatCode string `xml:"formatCode,attr"`
+}
+
+// xlsxCellXfs directly maps the cellXfs element. This element contains the
+// master formatting records (xf) which define the formatting applied to cells
+// in this workbook.
+type xlsxCellXfs struct {
+	Count int      `xml:"count,attr,omitempty"`
+	Xf    []xlsxXf `xml:"xf"`
+}
+
+// xlsxXf directly maps the xf element. A single xf element describes all of the
+// formatting for a cell.
+type xlsxXf struct {
+	NumFmtID          int  `xml:"numFmtId,attr"`
+	FontID            int  `xml:"fontId,attr"`
+	FillID            int  `xml:"fillId,attr"`
+	BorderID          int  `xml:"borderId,attr"`
+	XfID              int  `xml:"xfId,attr"`
+	ApplyNumberFormat bool `xml:"applyNumberFormat,attr,omitempty"`
+	ApplyFont         bool `xml:"applyFont,attr,omitempty"`
+}
+
+// xlsxMergeCells directly maps the mergeCells element. This collection
+// expresses all the merged cells in the sheet.
+type xlsxMergeCells struct {
+	Count int              `xml:"count,attr,omitempty"`
+	Cells []*xlsxMergeCell `xml:"mergeCell"`
+}
+
+// xlsxMergeCell directly maps the mergeCell element. A single merged cell.
+type xlsxMergeCell struct {
+	Ref string `xml:"ref,attr,omitempty"`
+}
@@ -0,0 +1,740 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+var (
+	ErrSharedFormula = errors.New("Cannot overwrite the master cell of a shared formula")
+	ErrInsertColumns = errors.New("Cannot insert columns into this sheet")
+)
+
+//修改一个工作表中的单元格并复制整个xlsx 文件：目标工作表逐个标记重写，
+//共享字符串表追加新的字符串，其他文件(样式、图表、其他工作表等)原样复制
+//
+//插入列时单元格、合并单元格、列宽、筛选、数据有效性、条件格式及该工作表公式中指向本工作表的引用随之移动；
+//其他工作表、定义名称、图表中指向该工作表的引用不会调整。
+//工作表中有表格(tableParts)、共享公式或插入位置在数组公式区域之内时Write 返回ErrInsertColumns
+type SheetPatch struct {
+	workbook *Workbook
+	sheet    SheetInfo
+	styles   *styles
+
+	cells   map[int]map[int]interface{} //行号、列序号(插入列之后的位置)对应的新值
+	inserts map[int]int                 //原工作表中的列序号及在其之前插入的列数
+
+	//Write 的状态
+	prefix      string         //工作表中元素的命名空间前缀
+	strings     []string       //追加到共享字符串表的字符串
+	stringIndex map[string]int //新字符串在strings 中的位置
+	baseStrings int            //原共享字符串表中<si>的数量
+	refDelta    int            //共享字符串引用数的变化，用于更新sst 的count
+	formulas    bool           //有公式单元格被覆盖或移动，计算链失效
+}
+
+//修改sheetName 工作表，为空时为第一个工作表，Write 之前不能关闭Workbook
+func (this *Workbook) PatchSheet(sheetName string) (*SheetPatch, error) {
+	sheet, err := this.findSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	styles, err := this.cellStyles()
+	if err != nil {
+		return nil, err
+	}
+	return &SheetPatch{
+		workbook: this,
+		sheet:    sheet,
+		styles:   styles,
+		cells:    make(map[int]map[int]interface{}),
+		inserts:  make(map[int]int),
+	}, nil
+}
+
+//修改当前工作表，需在Open 之后、Close 之前调用，可以在读取过程中调用
+//如在最后一列之后写入导入状态：p.SetCell(r.colOffset()+len(cols), rowNumber, "成功")
+func (this *reader) Patch() (*SheetPatch, error) {
+	if this.sheetData == nil {
+		return nil, ErrNotOpen
+	}
+	return this.workbook.PatchSheet(this.sheet)
+}
+
+//将ref 单元格(如"B7")的值修改为value，ref 为插入列之后的位置
+//value 的类型同writer 的WriteRow，nil 清除单元格的值；保留单元格原有的样式，
+//time.Time 在单元格原样式不是日期格式时使用工作簿中第一个日期样式，没有日期样式时写入文本
+//单元格是共享公式的主单元格时，其他单元格的公式依赖于它，Write 返回ErrSharedFormula
+func (this *SheetPatch) Set(ref string, value interface{}) error {
+	r, err := cellref.ParseA1(ref)
+	if err != nil {
+		return err
+	}
+	this.SetCell(r.Col, r.Row, value)
+	return nil
+}
+
+//col:从0开始的列序号，row:从1开始的行号，见Set
+func (this *SheetPatch) SetCell(col, row int, value interface{}) {
+	if this.cells[row] == nil {
+		this.cells[row] = make(map[int]interface{})
+	}
+	this.cells[row][col] = value
+}
+
+//在原工作表的第col 列(从0开始)之前插入n 个空列，之后的列右移，见SheetPatch 的限制
+func (this *SheetPatch) InsertColumns(col, n int) {
+	if n > 0 {
+		this.inserts[col] += n
+	}
+}
+
+//原工作表的列序号对应插入列之后的列序号
+func (this *SheetPatch) shift(col int) int {
+	n := col
+	for c, count := range this.inserts {
+		if c <= col {
+			n += count
+		}
+	}
+	if n >= cellref.MaxColumns {
+		n = cellref.MaxColumns - 1
+	}
+	return n
+}
+
+//移动单元格区域，可以是空格分隔的多个区域，如sqref="A1:B2 D4"，无法解析的部分保持原样
+func (this *SheetPatch) shiftRefs(refs string) string {
+	parts := strings.Fields(refs)
+	for i, p := range parts {
+		rng, err := cellref.ParseRange(p)
+		if err != nil || rng.WholeRows() {
+			continue
+		}
+		single := rng.Start == rng.End && !strings.Contains(p, ":")
+		rng.Start.Col, rng.End.Col = this.shift(rng.Start.Col), this.shift(rng.End.Col)
+		if single {
+			parts[i] = rng.Start.String()
+		} else {
+			parts[i] = rng.String()
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+//元素中需要随插入列移动的属性
+var shiftedAttrs = map[string]bool{"ref": true, "sqref": true, "activeCell": true, "topLeftCell": true}
+
+//插入列时移动元素中的引用
+func (this *SheetPatch) shiftAttrs(t *xml.StartElement) {
+	if len(this.inserts) == 0 {
+		return
+	}
+	if t.Name.Local == "col" {
+		//<col>的min、max 从1开始
+		for _, name := range []string{"min", "max"} {
+			if v, ok := attrValue(t.Attr, name); ok {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					t.Attr = setAttr(t.Attr, name, strconv.Itoa(this.shift(n-1)+1))
+				}
+			}
+		}
+		return
+	}
+	for i, a := range t.Attr {
+		if a.Name.Space == "" && shiftedAttrs[a.Name.Local] {
+			t.Attr[i].Value = this.shiftRefs(a.Value)
+		}
+	}
+}
+
+//插入列时需要移动其中文本的元素，f 为单元格公式，formula、formula1、formula2 为条件格式及数据有效性的公式，
+//x14 扩展中的xm:f、xm:sqref 分别为公式及区域
+var shiftedElements = map[string]bool{"f": true, "formula": true, "formula1": true, "formula2": true, "sqref": true}
+
+//移动元素中的文本，sqref 为空格分隔的区域，其他为公式
+func (this *SheetPatch) shiftText(local, text string) string {
+	if local == "sqref" {
+		return this.shiftRefs(text)
+	}
+	return this.shiftFormula(text)
+}
+
+//移动公式中指向本工作表的单元格引用，如SUM(B1:C3)、'会员'!$B$2、C:D，
+//字符串、函数名、结构化引用及指向其他工作表或外部工作簿的引用保持原样
+func (this *SheetPatch) shiftFormula(f string) string {
+	var buf strings.Builder
+	other := false    //当前引用指向其他工作表
+	external := false //外部工作簿，如[1]Sheet1!A1
+	for i := 0; i < len(f); {
+		c := f[i]
+		switch {
+		case c == '"' || c == '\'':
+			//字符串或带引号的工作表名称，引号本身以两个引号表示
+			j := i + 1
+			for j < len(f) {
+				if f[j] == c {
+					if j+1 < len(f) && f[j+1] == c {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			buf.WriteString(f[i:j])
+			if c == '\'' && j < len(f) && f[j] == '!' {
+				name := strings.ReplaceAll(strings.Trim(f[i:j], "'"), "''", "'")
+				other = external || strings.ContainsAny(name, "[]:") || !strings.EqualFold(name, this.sheet.Name)
+				external = false
+				buf.WriteByte('!')
+				j++
+			} else {
+				other = false
+			}
+			i = j
+		case c == '[':
+			j, depth := i, 0
+			for ; j < len(f); j++ {
+				if f[j] == '[' {
+					depth++
+				} else if f[j] == ']' {
+					if depth--; depth == 0 {
+						j++
+						break
+					}
+				}
+			}
+			buf.WriteString(f[i:j])
+			external = j < len(f) && (f[j] == '\'' || isFormulaNameChar(f[j]))
+			i = j
+		case isFormulaNameChar(c):
+			j := i
+			for j < len(f) && isFormulaNameChar(f[j]) {
+				j++
+			}
+			tok := f[i:j]
+			switch {
+			case j < len(f) && f[j] == '!':
+				other = external || !strings.EqualFold(tok, this.sheet.Name)
+				external = false
+				buf.WriteString(tok)
+				buf.WriteByte('!')
+				j++
+			case j < len(f) && (f[j] == '(' || f[j] == '['): //函数或表格
+				buf.WriteString(tok)
+			default:
+				inRange := (i > 0 && f[i-1] == ':') || (j < len(f) && f[j] == ':')
+				if !other {
+					tok = this.shiftFormulaRef(tok, inRange)
+				}
+				buf.WriteString(tok)
+				if j >= len(f) || f[j] != ':' {
+					other = false
+				}
+			}
+			i = j
+		default:
+			buf.WriteByte(c)
+			if c != ':' {
+				other = false
+			}
+			i++
+		}
+	}
+	return buf.String()
+}
+
+//公式中名称、引用及数字的字符，非ASCII 字符用于工作表名称
+func isFormulaNameChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '$' || c == '\\' || c >= 0x80
+}
+
+//移动公式中的一个引用，inRange 为true 时还可以是区域中的整列，如C:D 中的C
+func (this *SheetPatch) shiftFormulaRef(tok string, inRange bool) string {
+	if ref, err := cellref.ParseA1(tok); err == nil {
+		ref.Col = this.shift(ref.Col)
+		return ref.String()
+	}
+	if !inRange {
+		return tok
+	}
+	name := strings.TrimPrefix(tok, "$")
+	col, err := cellref.ColumnIndex(name)
+	if err != nil {
+		return tok
+	}
+	return tok[:len(tok)-len(name)] + cellref.ColumnName(this.shift(col))
+}
+
+//插入列时检查元素，不能调整的表格、共享公式、被插入位置拆开的数组公式返回ErrInsertColumns
+func (this *SheetPatch) checkInsert(t xml.StartElement) error {
+	switch t.Name.Local {
+	case "tablePart":
+		return fmt.Errorf("%w: sheet has tables", ErrInsertColumns)
+	case "f":
+		if v, _ := attrValue(t.Attr, "t"); v == "shared" {
+			return fmt.Errorf("%w: sheet has shared formulas", ErrInsertColumns)
+		}
+		if v, ok := attrValue(t.Attr, "ref"); ok {
+			if rng, err := cellref.ParseRange(v); err == nil && this.shift(rng.End.Col)-this.shift(rng.Start.Col) != rng.End.Col-rng.Start.Col {
+				return fmt.Errorf("%w: columns inserted into array formula %s", ErrInsertColumns, v)
+			}
+		}
+	}
+	return nil
+}
+
+//写入修改后的xlsx 文件，可以多次调用
+func (this *SheetPatch) Write(w io.Writer) error {
+	this.prefix, this.strings, this.refDelta, this.formulas = "", nil, 0, false
+	this.stringIndex = make(map[string]int)
+	if f := this.workbook.shareString; f != nil {
+		n, err := countSharedStrings(f)
+		if err != nil {
+			return err
+		}
+		this.baseStrings = n
+	}
+	sheetFile := this.workbook.file(this.sheet.path)
+	if sheetFile == nil {
+		return ErrSheetName
+	}
+	zw := zip.NewWriter(w)
+	if err := rewriteZipFile(zw, sheetFile, this.writeSheet); err != nil {
+		return err
+	}
+	//计算链中的单元格与工作表不一致时Excel 会报告文件损坏，删除后Excel 重新计算
+	dropCalcChain := this.formulas && this.workbook.calcChain != nil
+	for _, f := range this.workbook.reader.File {
+		var err error
+		switch {
+		case f == sheetFile:
+			continue
+		case f == this.workbook.shareString && (len(this.strings) > 0 || this.refDelta != 0):
+			err = rewriteZipFile(zw, f, this.writeSharedStrings)
+		case dropCalcChain && f == this.workbook.calcChain:
+			continue
+		case dropCalcChain && f.Name == "xl/_rels/workbook.xml.rels":
+			err = rewriteZipFile(zw, f, removeElements(func(t xml.StartElement) bool {
+				v, _ := attrValue(t.Attr, "Type")
+				return t.Name.Local == "Relationship" && v == relsNamespace+"/calcChain"
+			}))
+		case dropCalcChain && f.Name == "[Content_Types].xml":
+			err = rewriteZipFile(zw, f, removeElements(func(t xml.StartElement) bool {
+				v, _ := attrValue(t.Attr, "PartName")
+				return t.Name.Local == "Override" && v == "/"+this.workbook.calcChain.Name
+			}))
+		default:
+			err = copyZipFile(zw, f)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+//复制xml 并去掉match 的元素及其内容
+func removeElements(match func(t xml.StartElement) bool) func(dec *xml.Decoder, out *xmlWriter) error {
+	return func(dec *xml.Decoder, out *xmlWriter) error {
+		skip := 0 //正在跳过的元素的深度
+		for {
+			tok, err := dec.RawToken()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if skip > 0 || match(t) {
+					skip++
+					continue
+				}
+			case xml.EndElement:
+				if skip > 0 {
+					skip--
+					continue
+				}
+			default:
+				if skip > 0 {
+					continue
+				}
+			}
+			out.writeToken(tok)
+		}
+	}
+}
+
+//共享字符串表中<si>的数量
+func countSharedStrings(f *zip.File) (int, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	n, depth := 0, 0
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && t.Name.Local == "si" {
+				n++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+//带工作表命名空间前缀的元素名
+func (this *SheetPatch) name(local string) string {
+	if this.prefix == "" {
+		return local
+	}
+	return this.prefix + ":" + local
+}
+
+//有修改的行号，从小到大
+func (this *SheetPatch) rowNums() []int {
+	rows := make([]int, 0, len(this.cells))
+	for row := range this.cells {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+//行中有修改的列序号，从小到大
+func (this *SheetPatch) colNums(rowNum int) []int {
+	cols := make([]int, 0, len(this.cells[rowNum]))
+	for col := range this.cells[rowNum] {
+		cols = append(cols, col)
+	}
+	sort.Ints(cols)
+	return cols
+}
+
+//修改后的工作表区域
+func (this *SheetPatch) dimension(ref string) string {
+	d, err := cellref.ParseRange(ref)
+	if err != nil {
+		return ref
+	}
+	d.Start.Col, d.End.Col = this.shift(d.Start.Col), this.shift(d.End.Col)
+	for row, cols := range this.cells {
+		for col := range cols {
+			if col < d.Start.Col {
+				d.Start.Col = col
+			}
+			if col > d.End.Col {
+				d.End.Col = col
+			}
+		}
+		if row < d.Start.Row {
+			d.Start.Row = row
+		}
+		if row > d.End.Row {
+			d.End.Row = row
+		}
+	}
+	return d.String()
+}
+
+func (this *SheetPatch) writeSheet(dec *xml.Decoder, out *xmlWriter) error {
+	rows := this.rowNums()
+	var rowNum, prevCol, next int
+	var pending []int     //当前行中尚未输出的修改单元格
+	var inRow, moved bool //moved 为当前单元格因插入列而移动
+	var shifting string   //插入列时正在移动文本的元素
+	var text strings.Builder
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "worksheet":
+				this.prefix = t.Name.Space
+			case "dimension":
+				if v, ok := attrValue(t.Attr, "ref"); ok {
+					t.Attr = setAttr(t.Attr, "ref", this.dimension(v))
+				}
+				out.writeToken(t)
+				continue
+			case "row":
+				inRow = true
+				rowNum++
+				if v, ok := attrValue(t.Attr, "r"); ok {
+					if n, err := strconv.Atoi(v); err == nil {
+						rowNum = n
+					}
+				}
+				//工作表中没有的行
+				for ; next < len(rows) && rows[next] <= rowNum; next++ {
+					if rows[next] < rowNum {
+						this.writeRow(out, rows[next])
+					}
+				}
+				t.Attr = removeAttr(setAttr(t.Attr, "r", strconv.Itoa(rowNum)), "spans")
+				pending = this.colNums(rowNum)
+				prevCol = -1
+			case "c":
+				if !inRow {
+					break
+				}
+				col := prevCol + 1
+				if v, ok := attrValue(t.Attr, "r"); ok {
+					if ref, err := cellref.ParseA1(v); err == nil {
+						col = ref.Col
+					}
+				}
+				prevCol = col
+				moved = this.shift(col) != col
+				col = this.shift(col)
+				for len(pending) > 0 && pending[0] < col {
+					this.writeCell(out, rowNum, pending[0], nil)
+					pending = pending[1:]
+				}
+				t.Attr = setAttr(t.Attr, "r", cellref.Ref{Col: col, Row: rowNum}.String())
+				if len(pending) > 0 && pending[0] == col {
+					pending = pending[1:]
+					formula, master, err := skipCell(dec)
+					if err != nil {
+						return err
+					}
+					if master {
+						return fmt.Errorf("%w: %s", ErrSharedFormula, cellref.Ref{Col: col, Row: rowNum})
+					}
+					this.formulas = this.formulas || formula
+					if v, _ := attrValue(t.Attr, "t"); v == "s" {
+						this.refDelta--
+					}
+					this.writeCell(out, rowNum, col, t.Attr)
+					continue
+				}
+			}
+			if len(this.inserts) > 0 {
+				if err := this.checkInsert(t); err != nil {
+					return err
+				}
+				if t.Name.Local == "f" && inRow && moved {
+					this.formulas = true
+				}
+				if shiftedElements[t.Name.Local] {
+					shifting = t.Name.Local
+					text.Reset()
+				}
+			}
+			this.shiftAttrs(&t)
+			out.writeToken(t)
+		case xml.CharData:
+			if shifting != "" {
+				text.Write(t)
+				continue
+			}
+			out.writeToken(t)
+		case xml.EndElement:
+			if shifting != "" {
+				if text.Len() > 0 {
+					out.writeToken(xml.CharData(this.shiftText(shifting, text.String())))
+				}
+				shifting = ""
+			}
+			switch t.Name.Local {
+			case "row":
+				if inRow {
+					for _, col := range pending {
+						this.writeCell(out, rowNum, col, nil)
+					}
+					pending = nil
+					inRow = false
+				}
+			case "sheetData":
+				for ; next < len(rows); next++ {
+					this.writeRow(out, rows[next])
+				}
+			}
+			out.writeToken(t)
+		default:
+			out.writeToken(tok)
+		}
+	}
+}
+
+//跳过单元格的内容及结束标记，返回单元格是否有公式，及是否为共享公式的主单元格，即<f t="shared" ref="...">
+func skipCell(dec *xml.Decoder) (formula, master bool, err error) {
+	for depth := 1; depth > 0; {
+		tok, err := dec.RawToken()
+		if err != nil {
+			return false, false, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if t.Name.Local == "f" {
+				formula = true
+				v, _ := attrValue(t.Attr, "t")
+				_, ok := attrValue(t.Attr, "ref")
+				master = master || (v == "shared" && ok)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return formula, master, nil
+}
+
+//输出工作表中不存在的行
+func (this *SheetPatch) writeRow(out *xmlWriter, rowNum int) {
+	out.start(this.name("row"), attr("r", strconv.Itoa(rowNum)))
+	for _, col := range this.colNums(rowNum) {
+		this.writeCell(out, rowNum, col, nil)
+	}
+	out.end(this.name("row"))
+}
+
+//输出修改后的单元格，attrs 为原单元格的属性，保留其中的样式等属性，工作表中不存在的单元格为nil
+func (this *SheetPatch) writeCell(out *xmlWriter, rowNum, col int, attrs []xml.Attr) {
+	t, text, style := encodeValue(this.cells[rowNum][col], this.workbook.date1904)
+	if attrs == nil {
+		if text == "" {
+			return
+		}
+		attrs = []xml.Attr{attr("r", cellref.Ref{Col: col, Row: rowNum}.String())}
+	}
+	attrs = removeAttr(removeAttr(removeAttr(attrs, "t"), "cm"), "vm")
+	if style != styleDefault {
+		orig := 0
+		if v, ok := attrValue(attrs, "s"); ok {
+			orig, _ = strconv.Atoi(v)
+		}
+		if !this.styles.isDate(orig) {
+			if s := this.dateStyle(); s >= 0 {
+				attrs = setAttr(attrs, "s", strconv.Itoa(s))
+			} else {
+				t, text = "inlineStr", timeText(this.cells[rowNum][col], style)
+			}
+		}
+	}
+	if text == "" {
+		out.element(this.name("c"), attrs...)
+		return
+	}
+	if t == "inlineStr" && this.workbook.shareString != nil {
+		t, text = "s", strconv.Itoa(this.stringRef(text))
+	}
+	if t != "" {
+		attrs = append(attrs, attr("t", t))
+	}
+	out.start(this.name("c"), attrs...)
+	writeCellValue(out, this.prefix, t, text)
+	out.end(this.name("c"))
+}
+
+//没有日期样式时日期写入文本
+func timeText(v interface{}, style int) string {
+	if c, ok := v.(Cell); ok {
+		v = cellValue(c)
+	}
+	tm, _ := v.(time.Time)
+	if style == styleDateTime {
+		return tm.Format("2006-01-02 15:04:05")
+	}
+	return tm.Format("2006-01-02")
+}
+
+//工作簿中第一个日期格式的样式序号，没有时为-1
+func (this *SheetPatch) dateStyle() int {
+	if this.styles == nil {
+		return -1
+	}
+	for i, date := range this.styles.dates {
+		if date {
+			return i
+		}
+	}
+	return -1
+}
+
+//新字符串在共享字符串表中的序号
+func (this *SheetPatch) stringRef(s string) int {
+	this.refDelta++
+	i, ok := this.stringIndex[s]
+	if !ok {
+		i = len(this.strings)
+		this.stringIndex[s] = i
+		this.strings = append(this.strings, s)
+	}
+	return this.baseStrings + i
+}
+
+//在共享字符串表的最后追加新的字符串，并更新count 及uniqueCount
+func (this *SheetPatch) writeSharedStrings(dec *xml.Decoder, out *xmlWriter) error {
+	var prefix string
+	depth := 0
+	name := func(local string) string {
+		if prefix == "" {
+			return local
+		}
+		return prefix + ":" + local
+	}
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				prefix = t.Name.Space
+				t.Attr = incAttr(t.Attr, "count", this.refDelta)
+				if _, ok := attrValue(t.Attr, "uniqueCount"); ok {
+					t.Attr = setAttr(t.Attr, "uniqueCount", strconv.Itoa(this.baseStrings+len(this.strings)))
+				}
+			}
+			out.writeToken(t)
+		case xml.EndElement:
+			if depth == 1 {
+				for _, s := range this.strings {
+					out.start(name("si"))
+					out.textElement(name("t"), escapeCellText(s), spaceAttr(s)...)
+					out.end(name("si"))
+				}
+			}
+			depth--
+			out.writeToken(t)
+		default:
+			out.writeToken(tok)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var patchFixture = fixture{
+	sheets: []fixtureSheet{
+		{name: "会员", head: `<dimension ref="A1:B4"/><cols><col min="2" max="3" width="20" customWidth="1"/></cols>`,
+			sheetData: `<row r="1" spans="1:2"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>` +
+				`<row r="2" spans="1:2"><c r="A2" t="s" s="2"><v>2</v></c><c r="B2" t="s"><v>3</v></c></row>` +
+				`<row r="4" spans="1:2"><c r="A4"><v>7</v></c><c r="B4"><f>A4*2</f><v>14</v></c></row>`,
+			tail: `<mergeCells count="1"><mergeCell ref="A4:B4"/></mergeCells>` +
+				`<dataValidations count="1"><dataValidation type="list" sqref="B2:B9 A1"><formula1>"x,y"</formula1></dataValidation></dataValidations>`},
+		{name: "Sheet2", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c></row>`},
+	},
+	strings: []string{"姓名", "备注", "张三", "旧值"},
+	files: map[string]string{
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<cellXfs count="3"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="0" fontId="1"/></cellXfs></styleSheet>`,
+		"xl/theme/theme1.xml": `<a:theme xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" name="Office"/>`,
+	},
+}
+
+func TestSheetPatch_Write(t *testing.T) {
+	data := patchFixture.bytes(t)
+	r := ReaderFromBytes(data, "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.Patch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.InsertColumns(1, 1) //在"备注"之前插入"状态"列
+	p.Set("B1", "状态")
+	p.Set("B2", "成功")
+	p.Set("C2", 20)
+	p.SetCell(3, 3, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC))
+	p.Set("A4", nil)
+	var buf bytes.Buffer
+	if err = p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	orig, files := unzipAll(t, data), unzipAll(t, buf.Bytes())
+	for _, name := range []string{"xl/worksheets/sheet2.xml", "xl/styles.xml", "xl/theme/theme1.xml", "xl/workbook.xml"} {
+		if files[name] != orig[name] {
+			t.Errorf("%s changed", name)
+		}
+	}
+	//未修改的文件原样复制压缩数据及文件头
+	raw := func(data []byte, name string) (zip.FileHeader, string) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range zr.File {
+			if f.Name == name {
+				rc, _ := f.OpenRaw()
+				b, _ := io.ReadAll(rc)
+				return f.FileHeader, string(b)
+			}
+		}
+		t.Fatalf("%s not found", name)
+		return zip.FileHeader{}, ""
+	}
+	h1, b1 := raw(data, "xl/theme/theme1.xml")
+	h2, b2 := raw(buf.Bytes(), "xl/theme/theme1.xml")
+	if b1 != b2 || h1.Method != h2.Method || h1.CRC32 != h2.CRC32 || !h1.Modified.Equal(h2.Modified) || h1.Comment != h2.Comment {
+		t.Errorf("theme1.xml header=%+v, want %+v", h2, h1)
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, s := range []string{`<dimension ref="A1:D4"/>`, `<col min="3" max="4" width="20" customWidth="1"/>`, `<mergeCell ref="A4:C4"/>`,
+		`sqref="C2:C9 A1"`, `<c r="A2" t="s" s="2">`, `<c r="D3" s="1"><v>45418</v></c>`, `<c r="A4"/>`, `<c r="C4"><f>A4*2</f>`} {
+		if !strings.Contains(sheet, s) {
+			t.Errorf("sheet1.xml missing %s\n%s", s, sheet)
+		}
+	}
+	if strings.Contains(sheet, "spans=") {
+		t.Error("spans should be removed")
+	}
+	if sst := files["xl/sharedStrings.xml"]; !strings.Contains(sst, `count="5" uniqueCount="6"`) || !strings.HasSuffix(sst, `<si><t>状态</t></si><si><t>成功</t></si></sst>`) {
+		t.Errorf("sharedStrings.xml=%s", sst)
+	}
+
+	out := ReaderFromBytes(buf.Bytes(), "", false)
+	defer out.Close()
+	out.Open()
+	var rows [][]string
+	for out.Next() {
+		rows = append(rows, out.Row())
+	}
+	want := [][]string{{"姓名", "状态", "备注"}, {"张三", "成功", "20"}, {"", "", "", "45418"}, {"", "", "14"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows=%q", rows)
+	}
+}
+
+func TestSheetPatch_InlineStrings(t *testing.T) {
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>姓名</t></is></c></row>`}}}
+	wb, err := OpenWorkbookFromBytes(f.bytes(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	p, err := wb.PatchSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//没有共享字符串表时写入内联字符串，没有日期样式时日期写入文本
+	p.Set("B1", " 导入状态 ")
+	p.Set("C1", time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	var buf bytes.Buffer
+	if err = p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := unzipAll(t, buf.Bytes())
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		t.Error("sharedStrings.xml should not be added")
+	}
+	r := ReaderFromBytes(buf.Bytes(), "", true)
+	defer r.Close()
+	if cols, err := r.Open(); err != nil || !reflect.DeepEqual(cols, []string{"姓名", " 导入状态 ", "2024-05-06 07:08:09"}) {
+		t.Errorf("cols=%q err=%v", cols, err)
+	}
+}
+
+func TestSheetPatch_ShiftFormula(t *testing.T) {
+	p := &SheetPatch{sheet: SheetInfo{Name: "会员"}, inserts: map[int]int{1: 1}} //在B列之前插入一列
+	cases := []struct{ formula, want string }{
+		{"A4*2", "A4*2"},
+		{"SUM(B1:C3)+$B$2", "SUM(C1:D3)+$C$2"},
+		{"'会员'!B2+会员!$B2", "'会员'!C2+会员!$C2"},
+		{"Sheet2!B1+'My Sheet'!B1:C1+[1]会员!B1", "Sheet2!B1+'My Sheet'!B1:C1+[1]会员!B1"},
+		{`IF(B1="B1",LOG10(B1),"x""B2")`, `IF(C1="B1",LOG10(C1),"x""B2")`},
+		{"SUM(B:C)+SUM(2:3)+COUNTA($A:$B)", "SUM(C:D)+SUM(2:3)+COUNTA($A:$C)"},
+		{"Table1[[#This Row],[B1]]*1.5E+3", "Table1[[#This Row],[B1]]*1.5E+3"},
+	}
+	for _, c := range cases {
+		if got := p.shiftFormula(c.formula); got != c.want {
+			t.Errorf("shiftFormula(%q)=%q, want %q", c.formula, got, c.want)
+		}
+	}
+}
+
+func TestSheetPatch_Formulas(t *testing.T) {
+	write := func(sheet fixtureSheet, fn func(p *SheetPatch)) (string, error) {
+		wb, err := OpenWorkbookFromBytes(fixture{sheets: []fixtureSheet{sheet}}.bytes(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer wb.Close()
+		p, err := wb.PatchSheet("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn(p)
+		var buf bytes.Buffer
+		if err = p.Write(&buf); err != nil {
+			return "", err
+		}
+		return unzipAll(t, buf.Bytes())["xl/worksheets/sheet1.xml"], nil
+	}
+	insert := func(p *SheetPatch) { p.InsertColumns(1, 1) }
+
+	sheet, err := write(fixtureSheet{name: "Sheet1",
+		sheetData: `<row r="1"><c r="A1"><v>1</v></c><c r="B1"><v>2</v></c><c r="C1"><f>SUM(A1:B1)</f><v>3</v></c></row>` +
+			`<row r="2"><c r="A2"><f t="array" ref="A2:A3">B1*2</f></c></row>`,
+		tail: `<conditionalFormatting sqref="B1"><cfRule type="expression" priority="1"><formula>$B$1&gt;1</formula></cfRule></conditionalFormatting>` +
+			`<dataValidations count="1"><dataValidation type="list" sqref="A5"><formula1>$B$1:$B$3</formula1></dataValidation></dataValidations>`}, insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<c r="D1"><f>SUM(A1:C1)</f>`, `<f t="array" ref="A2:A3">C1*2</f>`, `<conditionalFormatting sqref="C1">`,
+		`<formula>$C$1&gt;1</formula>`, `<formula1>$C$1:$C$3</formula1>`} {
+		if !strings.Contains(sheet, s) {
+			t.Errorf("sheet1.xml missing %s\n%s", s, sheet)
+		}
+	}
+
+	for _, s := range []fixtureSheet{
+		{name: "Sheet1", sheetData: `<row r="1"><c r="C1"><f t="shared" ref="C1:C3" si="0">A1</f></c></row>`},
+		{name: "Sheet1", sheetData: `<row r="1"><c r="A1"><f t="array" ref="A1:C1">1</f></c></row>`},
+		{name: "Sheet1", sheetData: `<row r="1"/>`, tail: `<tableParts count="1"><tablePart r:id="rId1"/></tableParts>`},
+	} {
+		if _, err = write(s, insert); !errors.Is(err, ErrInsertColumns) {
+			t.Errorf("%s%s: %v", s.sheetData, s.tail, err)
+		}
+	}
+
+	shared := fixtureSheet{name: "Sheet1", sheetData: `<row r="1"><c r="A1"><f t="shared" ref="A1:A2" si="0">B1</f><v>0</v></c></row>` +
+		`<row r="2"><c r="A2"><f t="shared" si="0"/><v>0</v></c></row>`}
+	if _, err = write(shared, func(p *SheetPatch) { p.Set("A1", 1) }); !errors.Is(err, ErrSharedFormula) {
+		t.Errorf("Set shared formula master: %v", err)
+	}
+	if sheet, err = write(shared, func(p *SheetPatch) { p.Set("A2", 1) }); err != nil || !strings.Contains(sheet, `<c r="A2"><v>1</v></c>`) {
+		t.Errorf("Set shared formula dependent: %v\n%s", err, sheet)
+	}
+}
+
+func TestSheetPatch_CalcChain(t *testing.T) {
+	data := fixture{sheets: []fixtureSheet{{name: "Sheet1",
+		sheetData: `<row r="1"><c r="A1"><v>1</v></c><c r="B1"><f>A1*2</f><v>2</v></c><c r="C1"><v>3</v></c></row>`}},
+		bookRels: `<Relationship Id="rIdCalc" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/calcChain" Target="calcChain.xml"/>`,
+		files: map[string]string{
+			"xl/calcChain.xml": `<calcChain xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><c r="B1" i="1"/></calcChain>`,
+			"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/>` +
+				`<Override PartName="/xl/calcChain.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.calcChain+xml"/></Types>`,
+		}}.bytes(t)
+	write := func(fn func(p *SheetPatch)) map[string]string {
+		wb, err := OpenWorkbookFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer wb.Close()
+		p, err := wb.PatchSheet("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn(p)
+		var buf bytes.Buffer
+		if err = p.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return unzipAll(t, buf.Bytes())
+	}
+	for name, fn := range map[string]func(p *SheetPatch){
+		"set formula": func(p *SheetPatch) { p.Set("B1", 5) },
+		"insert":      func(p *SheetPatch) { p.InsertColumns(0, 1) },
+	} {
+		files := write(fn)
+		if _, ok := files["xl/calcChain.xml"]; ok || strings.Contains(files["xl/_rels/workbook.xml.rels"], "calcChain") ||
+			strings.Contains(files["[Content_Types].xml"], "calcChain") || !strings.Contains(files["xl/_rels/workbook.xml.rels"], "worksheets/sheet1.xml") {
+			t.Errorf("%s: calcChain not removed: %q", name, files)
+		}
+	}
+	//公式单元格不变时保留计算链
+	files := write(func(p *SheetPatch) { p.Set("C1", 4); p.InsertColumns(2, 1) })
+	if _, ok := files["xl/calcChain.xml"]; !ok || !strings.Contains(files["[Content_Types].xml"], "/xl/calcChain.xml") {
+		t.Errorf("calcChain removed: %q", files)
+	}
+}
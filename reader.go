@@ -7,10 +7,12 @@ import (
 	"encoding/xml"
 	"errors"
 	"io"
-	"math"
+	"io/fs"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
 )
 
 type Policy int
@@ -18,35 +20,77 @@ type Policy int
 const (
 	LowMemery = Policy(0) //时间复杂度O(n^2) 空间复杂度O(1)
 	Fast      = Policy(1) //时间复杂度O（n） 空间复杂度O(n)
+	Indexed   = Policy(2) //时间复杂度O(n) 空间复杂度O(1)，共享字符串解压到临时文件，内存中只缓存最近使用的字符串
 
 )
 
 var (
 	tFlag        = []byte("</t>")
 	rowFlag      = []byte("</row>")
-	colR         = regexp.MustCompile(`\d+$`)
 	ErrFileType  = errors.New("File type must be xlsx")
 	ErrSheetName = errors.New("Could not find specific sheet")
 	ErrCols      = errors.New("First row does not match Cols")
+	ErrNotOpen   = errors.New("Reader is not open")
 )
 
 type reader struct {
-	fileName      string //xlsx 文件路径及名称
-	sheetName     string //读取指定的工作表，如果为空则读取第一个
-	policy        Policy //读取策略，快速读取还是小内存读取
-	firstRowIsCol bool   //首行数据作为列名
+	fileName      string         //xlsx 文件路径及名称
+	sheetName     string         //读取指定的工作表，如果为空则读取第一个
+	sheet         string         //Open 后实际读取的工作表名称
+	policy        Policy         //读取策略，快速读取还是小内存读取
+	firstRowIsCol bool           //首行数据作为列名
+	formatted     bool           //FetchRow 返回按数字格式渲染后的文本
+	fillBlankRows bool           //为工作表中缺少的行补充空行
+	width         int            //firstRowIsCol 为false 时的行宽度，见SetRowWidth
+	rng           *cellref.Range //只读取该区域内的单元格，nil 为整个工作表
+	headerRow     int            //列名所在的行号，0 为第一个<row>
+	headerScan    int            //自动识别列名行时扫描的行数，0 为不识别
+	headerRows    int            //列名占用的行数，见SetHeaderRows
+	dupNames      DuplicateNames //重复列名的处理方式
+	blankNames    BlankNames     //空列名的处理方式
+	headerSpecs   []HeaderSpec   //OpenAndValidCols 等指定的列，用于识别列名行
 
-	reader      *zip.ReadCloser
+	openZip     func() (*zip.Reader, io.Closer, error) //打开xlsx压缩包，不同的数据源有不同的实现
+	workbook    *Workbook
+	ownWorkbook bool //workbook 由Open 创建，Close 时一并关闭
 	shareString *zip.File
 	sheetData   *zip.File
 
 	sheetReader     io.ReadCloser
 	sheetXmlDecoder *xml.Decoder
 	cols            []string //firstRowIsCol 为true 时 获取到的列表集合
+	headerRowNum    int      //列名所在的行号，多行列名时为最后一行
 	columnMaps      map[int]int
 	maxIndex        int
 
-	stringCache []string //Fast策略string缓存
+	stringCache []string     //Fast策略string缓存
+	stringIndex *stringIndex //Indexed策略的字符串索引
+	styles      *styles      //用于识别日期时间单元格
+
+	//当前行
+	dimension string //<dimension>的ref，如A1:H500
+	rowNum    int    //最近读取的<row>的行号，从1开始
+	curRow    int    //当前行的行号，补充空行时小于rowNum
+	blankRow  bool   //当前行是补充的空行
+	pending   bool   //已读取的<row>在补充的空行之后输出
+	prevCol   int    //上一个单元格的列序号，单元格缺少r 属性时使用
+	rowCells  []Cell //有值的单元格
+
+	//Next 迭代状态
+	row      []string //Row 的缓存
+	cells    []Cell   //Cells 的缓存
+	iterErr  error
+	iterDone bool
+
+	structPlan *structPlan    //FetchStruct 字段与列的对应关系
+	colIndex   map[string]int //Record 的列名索引
+
+	//校验状态
+	spec          *HeaderSpec            //OpenAndValidSpec 等匹配的列，包含校验规则
+	maxErrors     int                    //错误数达到该值时停止读取，0 为不限制
+	violations    []*CellError           //全部校验错误
+	rowViolations RowError               //当前行的校验错误
+	uniques       map[int]map[string]int //Unique 列已出现的值及行号
 
 	//LowMemery策略 的io指针缓存，一般情况下不需要每次都new
 	stringReader io.ReadCloser
@@ -60,61 +104,128 @@ type reader struct {
 func Reader(fileName, sheetName string, firstRowIsCol bool) *reader {
 	return newReader(fileName, sheetName, firstRowIsCol, Fast)
 }
+
+//r:xlsx 文件内容,size:文件大小,适用于上传的文件或其他实现了io.ReaderAt的数据源
+func ReaderFromReaderAt(r io.ReaderAt, size int64, sheetName string, firstRowIsCol bool) *reader {
+	this := newReader("", sheetName, firstRowIsCol, Fast)
+	this.openZip = func() (*zip.Reader, io.Closer, error) {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return nil, nil, ErrFileType
+		}
+		return zr, nil, nil
+	}
+	return this
+}
+
+//data:内存中的xlsx 文件内容
+func ReaderFromBytes(data []byte, sheetName string, firstRowIsCol bool) *reader {
+	return ReaderFromReaderAt(bytes.NewReader(data), int64(len(data)), sheetName, firstRowIsCol)
+}
+
+//fsys:文件系统(embed.FS、os.DirFS等),name:xlsx 文件在fsys中的路径
+func ReaderFromFS(fsys fs.FS, name, sheetName string, firstRowIsCol bool) *reader {
+	this := newReader(name, sheetName, firstRowIsCol, Fast)
+	this.openZip = func() (*zip.Reader, io.Closer, error) {
+		return openFSZip(fsys, name)
+	}
+	return this
+}
+
 func newReader(fileName, sheetName string, firstRowIsCol bool, policy Policy) *reader {
-	return &reader{
+	this := &reader{
 		fileName:      fileName,
 		sheetName:     sheetName,
 		policy:        policy,
 		rowCount:      -1,
 		firstRowIsCol: firstRowIsCol,
 	}
+	this.openZip = func() (*zip.Reader, io.Closer, error) {
+		return openFileZip(this.fileName)
+	}
+	return this
+}
+
+func openFileZip(fileName string) (*zip.Reader, io.Closer, error) {
+	if !strings.HasSuffix(strings.ToLower(fileName), ".xlsx") {
+		return nil, nil, ErrFileType
+	}
+	rc, err := zip.OpenReader(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &rc.Reader, rc, nil
+}
+
+//fs.File 实现了io.ReaderAt 时直接读取，否则读入内存
+func openFSZip(fsys fs.FS, name string) (*zip.Reader, io.Closer, error) {
+	if !strings.HasSuffix(strings.ToLower(name), ".xlsx") {
+		return nil, nil, ErrFileType
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ra, ok := f.(io.ReaderAt); ok {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		zr, err := zip.NewReader(ra, info.Size())
+		if err != nil {
+			f.Close()
+			return nil, nil, ErrFileType
+		}
+		return zr, f, nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, ErrFileType
+	}
+	return zr, nil, nil
 }
 
 //打开要读取的工作表，并根据firstRowIsCol 返回列集合
 //如果 firstRowIsCol为false,则cols为nil
-//todo 处理列名为空的列
+//重复及空列名的处理见SetDuplicateNames、SetBlankNames
 func (this *reader) Open() (cols []string, err error) {
-	if !strings.HasSuffix(strings.ToLower(this.fileName), ".xlsx") {
-		return nil, ErrFileType
+	if this.workbook == nil {
+		if this.workbook, err = newWorkbook(this.openZip()); err != nil {
+			return
+		}
+		this.workbook.policy = this.policy
+		this.ownWorkbook = true
 	}
-	this.reader, err = zip.OpenReader(this.fileName)
+	sheet, err := this.workbook.findSheet(this.sheetName)
 	if err != nil {
 		return
 	}
-	var workbook xlsxWorkbook
-	var bookrel xlsxWorkbookRels
-	files := this.reader.File
-	//解析workbook
-	//workbook 文件较小所以可以全量解析
-	for _, file := range files {
-		if file.Name == "xl/workbook.xml" {
-			err = decodeZip(file, &workbook)
-			if err != nil {
-				return
-			}
-		} else if file.Name == "xl/_rels/workbook.xml.rels" {
-			err = decodeZip(file, &bookrel)
-			if err != nil {
-				return
-			}
-		}
-	}
-	sName := this.getSheetPath(workbook, bookrel)
-	if sName == "" {
+	this.sheet = sheet.Name
+	//得到工作表和字符串存储的xml
+	this.shareString = this.workbook.shareString
+	if this.sheetData = this.workbook.file(sheet.path); this.sheetData == nil {
 		err = ErrSheetName
 		return
 	}
-	//得到工作表和字符串存储的xml
-	for _, file := range files {
-		if file.Name == "xl/sharedStrings.xml" {
-			this.shareString = file
-		} else if file.Name == sName {
-			this.sheetData = file
+	//先解析出string
+	switch this.policy {
+	case Fast:
+		if this.stringCache, err = this.workbook.sharedStrings(); err != nil {
+			return
+		}
+	case Indexed:
+		if this.stringIndex, err = this.workbook.indexedStrings(); err != nil {
+			return
 		}
 	}
-	//先解析出string
-	if this.policy == Fast {
-		this.decodeString1()
+	if this.styles, err = this.workbook.cellStyles(); err != nil {
+		return
 	}
 	this.sheetReader, err = this.sheetData.Open()
 	if err != nil {
@@ -124,101 +235,37 @@ func (this *reader) Open() (cols []string, err error) {
 
 	//读取首行作为列
 	if this.firstRowIsCol {
-		var valueFlag int
-		var isString bool
-	loop:
-		for {
-			t, _ := this.sheetXmlDecoder.Token()
-			if t == nil {
-				break
-			}
-			switch token := t.(type) {
-			case xml.StartElement:
-				switch token.Name.Local {
-				case "row":
-					cols = []string{}
-				case "c":
-					isString = false
-					for _, v := range token.Attr {
-						if v.Name.Local == "t" && v.Value == "s" {
-							isString = true
-							break
-						}
-					}
-				case "v":
-					valueFlag = 1
-				}
-			case xml.EndElement:
-				name := token.Name.Local
-				if name == "row" {
-					break loop
-				}
-			case xml.CharData:
-				if valueFlag == 1 {
-					value := string([]byte(token))
-					if isString {
-						index, _ := strconv.Atoi(value)
-						if this.policy == Fast {
-							value = this.stringCache[index]
-						} else {
-							value = this.findString(index)
-						}
-					}
-					cols = append(cols, value)
-				}
-			}
+		if cols, err = this.readHeader(); err != nil {
+			return
+		}
+		if cols, err = this.resolveNames(cols); err != nil {
+			return
 		}
 		this.cols = cols
+		this.headerRowNum = this.curRow
+		off := this.colOffset()
 		this.columnMaps = make(map[int]int, len(cols))
 		for i := 0; i < len(cols); i++ {
-			this.columnMaps[i] = i
+			this.columnMaps[off+i] = i
 		}
-		this.maxIndex = len(cols) - 1
+		this.maxIndex = off + len(cols) - 1
+	} else if this.rng != nil {
+		//补充空行从区域的第一行开始
+		this.curRow = this.rng.Start.Row - 1
 	}
 	return
 }
 
-//打开要读取的工作表，并根据输入的cols校验excel模板是否正确
-//firstRowIsCol 参数必须为true
-func (this *reader) OpenAndValidCols(cols []string) error {
-	if !this.firstRowIsCol {
-		return errors.New("firstRowIsCol must be true")
-	}
-	if _, err := this.Open(); err != nil {
-		return err
-	}
-	if err := this.checkCols(cols); err != nil {
-		return err
-	}
-	this.cols = cols
-	return nil
+//共享字符串的读取策略，需在Open 之前调用，默认为Fast
+//由Workbook 创建的读取器使用Workbook 的策略，见Workbook.SetPolicy
+func (this *reader) SetPolicy(policy Policy) {
+	this.policy = policy
 }
 
-func (this *reader) checkCols(cols []string) error {
-	l := len(cols)
-	if l > len(this.cols) {
-		return ErrCols
-	}
-	this.columnMaps = make(map[int]int, l)
-	var isFound bool
-	for i, v := range cols {
-		isFound = false
-		for j, c := range this.cols {
-			if v == c {
-				this.columnMaps[j] = i
-				if this.maxIndex < j {
-					this.maxIndex = j
-				}
-				isFound = true
-				break
-			}
-		}
-		//第一行中不包含传入的列
-		if !isFound {
-			return ErrCols
-		}
-	}
-	return nil
+//打开要读取的工作表，并根据输入的cols校验excel模板是否正确
+//firstRowIsCol 参数必须为true，比较时忽略首尾空白、大小写、全角半角及必填标记*，见NewHeaderSpec
+func (this *reader) OpenAndValidCols(cols []string) error {
+	return this.OpenAndValidSpec(NewHeaderSpec(cols...))
 }
 
 func (this *reader) Close() error {
@@ -228,120 +275,89 @@ func (this *reader) Close() error {
 	if this.sheetReader != nil {
 		this.sheetReader.Close()
 	}
-	if this.reader != nil {
-		return this.reader.Close()
+	if this.ownWorkbook {
+		return this.workbook.Close()
 	}
 	return nil
 }
 
 //逐行读取，如果rowAction中返回 err!=nil 则中断
-func (this *reader) FetchRow(rowAction func(row []string) error) (err error) {
+func (this *reader) FetchRow(rowAction func(row []string) error) error {
 	//解析工作表，这里如果全量解析内部使用递归算法，所以只能逐行解析，避免OOM kill
-	//flag: 0 ignore,1 elementStart, 2 elementEnd
-	var rowFlag, valueFlag int8
-	var prevColIndex, colIndex, realIndex int
-	var isString, ok bool
-	var row []string
-	//逐行读取sheet
-loop:
-	for {
-		t, _ := this.sheetXmlDecoder.Token()
-		if t == nil {
-			break
-		}
-		switch token := t.(type) {
-		case xml.StartElement:
-			switch token.Name.Local {
-			case "row":
-				rowFlag = 1
-				prevColIndex = 0
-				if this.firstRowIsCol {
-					row = make([]string, len(this.cols))
-
-				} else {
-					row = []string{}
-				}
-			case "c":
-				if rowFlag == 1 {
-					isString = false
-					for _, v := range token.Attr {
-						if v.Name.Local == "t" && v.Value == "s" {
-							isString = true
-						} else if v.Name.Local == "r" {
-							colIndex = getIndex(v.Value)
-							//忽略超过指定列的数据
-							if this.firstRowIsCol && colIndex > this.maxIndex {
-								rowFlag = 0
-							}
-						}
-					}
-				}
-			case "v":
-				if rowFlag == 1 {
-					valueFlag = 1
-				}
-			}
-		case xml.EndElement:
-			switch token.Name.Local {
-			case "row":
-				rowFlag = 2
-			case "c":
-				prevColIndex = colIndex
-			case "v":
-				valueFlag = 2
-			case "sheetData":
-				break loop
-			}
-		case xml.CharData:
-			if valueFlag == 1 {
-				if this.firstRowIsCol {
-					if realIndex, ok = this.columnMaps[colIndex]; !ok {
-						break
-					}
-				}
-				value := string([]byte(token))
-				if isString {
-					i, _ := strconv.Atoi(value)
-					if this.policy == Fast {
-						value = this.stringCache[i]
-					} else {
-						value = this.findString(i)
-					}
-				}
-				if this.firstRowIsCol {
-					row[realIndex] = value
-				} else {
-					for i := prevColIndex; i <= colIndex; i++ {
-						if i == colIndex {
-							row = append(row, value)
-						} else {
-							row = append(row, "")
-						}
-					}
-				}
-			}
+	for this.Next() {
+		if err := rowAction(this.Row()); err != nil {
+			return err
 		}
+	}
+	return this.Err()
+}
 
-		if rowFlag == 2 {
-			if er := rowAction(row); er != nil {
-				return er
-			}
+//逐行读取，rowNumber 为工作表中的行号，从1开始，如果rowAction中返回 err!=nil 则中断
+func (this *reader) FetchRowWithNumber(rowAction func(rowNumber int, row []string) error) error {
+	for this.Next() {
+		if err := rowAction(this.curRow, this.Row()); err != nil {
+			return err
 		}
 	}
+	return this.Err()
+}
 
-	return
+const (
+	WidthAuto      = 0  //按行中最后一个有值的单元格，各行长度可能不同
+	WidthDimension = -1 //按工作表<dimension>中的列范围
+	WidthHeader    = -2 //按首行的长度
+)
+
+//firstRowIsCol 为false 时固定每行的长度，需在读取之前调用
+//width 大于0 时为指定的列数，也可以是WidthDimension、WidthHeader
+//行中少于该宽度时补空字符串，超出的列被忽略；firstRowIsCol 为true 时行长度总是列数
+func (this *reader) SetRowWidth(width int) {
+	this.width = width
 }
 
-func getIndex(colId string) int {
-	s := colR.ReplaceAllString(colId, "")
-	index := 0
-	for i := len(s) - 1; i >= 0; i-- {
-		x := int(s[i]) - 64
-		l := len(s) - i - 1
-		index += x*int(math.Pow(26, float64(l))) - 1
+//fill 为true 时工作表中缺少的行(没有任何数据的行)以空行输出，使行号连续
+//firstRowIsCol 为true 时空行长度为列数，否则为空切片
+func (this *reader) SetFillBlankRows(fill bool) {
+	this.fillBlankRows = fill
+}
+
+//只读取区域内的单元格，需在Open 之前调用
+//ref 如B3:H500、C:E、3:9，可以带工作表名称，如Sheet1!B3:H500、'My Sheet'!B3:H500，此时替换sheetName
+//firstRowIsCol 为true 时区域内的第一行作为列名；输出的行从区域的第一列开始
+func (this *reader) SetRange(ref string) error {
+	sheet, ref := cellref.SplitSheet(ref)
+	rng, err := cellref.ParseRange(ref)
+	if err != nil {
+		return err
+	}
+	if sheet != "" {
+		this.sheetName = sheet
 	}
-	index += len(s) - 1
-	return index
+	this.SetRangeRef(rng)
+	return nil
+}
+
+//同SetRange，使用已解析的区域
+func (this *reader) SetRangeRef(rng cellref.Range) {
+	this.rng = &rng
+}
+
+//输出的行中第一列在工作表中的列序号
+func (this *reader) colOffset() int {
+	if this.rng == nil {
+		return 0
+	}
+	return this.rng.Start.Col
+}
+
+//逐行读取带类型的单元格，对齐方式同FetchRow，如果rowAction中返回 err!=nil 则中断
+func (this *reader) FetchCells(rowAction func(cells []Cell) error) error {
+	for this.Next() {
+		if err := rowAction(this.Cells()); err != nil {
+			return err
+		}
+	}
+	return this.Err()
 }
 
 //获取总行数,如果需要时则获取
@@ -350,6 +366,10 @@ func (this *reader) GetRowCount() (c int, err error) {
 		c = this.rowCount
 		return
 	}
+	if this.sheetData == nil {
+		err = ErrNotOpen
+		return
+	}
 	r, err := this.sheetData.Open()
 	if err != nil {
 		return
@@ -369,27 +389,6 @@ func (this *reader) GetRowCount() (c int, err error) {
 	return
 }
 
-func (this *reader) getSheetPath(workbook xlsxWorkbook, bookrel xlsxWorkbookRels) string {
-	var rid string
-	if this.sheetName != "" {
-		for _, sheet := range workbook.Sheets.Sheet {
-			if sheet.Name == this.sheetName {
-				rid = sheet.ID
-				break
-			}
-		}
-	}
-	if rid == "" {
-		rid = workbook.Sheets.Sheet[0].ID
-	}
-	for _, rel := range bookrel.Relationships {
-		if rel.ID == rid {
-			return "xl/" + rel.Target
-		}
-	}
-	return ""
-}
-
 //全量解析XML
 func decodeZip(f *zip.File, v interface{}) error {
 	rc, err := f.Open()
@@ -406,6 +405,9 @@ func decodeZip(f *zip.File, v interface{}) error {
 //todo 如果文件较大可以使用分片多协程查找
 func (this *reader) findString(i int) string {
 	//保存上一次的查找位置，一般情况下，不需要重头开始查
+	if this.shareString == nil {
+		return ""
+	}
 	if this.bufReader == nil || i <= this.prevIndex {
 		if this.stringReader != nil {
 			this.stringReader.Close()
@@ -437,6 +439,9 @@ func (this *reader) findString(i int) string {
 
 //解析shareString到缓存
 func (this *reader) decodeString() error {
+	if this.shareString == nil {
+		return nil
+	}
 	rc, err := this.shareString.Open()
 	if err != nil {
 		return err
@@ -472,30 +477,60 @@ func (this *reader) decodeString() error {
 }
 
 //解析shareString到缓存（标准库xml解析）
-func (this *reader) decodeString1() error {
-	rc, err := this.shareString.Open()
+func (this *reader) decodeString1() (err error) {
+	this.stringCache, err = decodeSharedStrings(this.shareString)
+	return
+}
+
+func decodeSharedStrings(shareString *zip.File) ([]string, error) {
+	var stringCache []string
+	index := 0
+	err := scanSharedStrings(shareString, func(n int) {
+		stringCache = make([]string, n)
+	}, func(s string) error {
+		if index < len(stringCache) {
+			stringCache[index] = s
+		} else {
+			stringCache = append(stringCache, s)
+		}
+		index++
+		return nil
+	})
+	return stringCache, err
+}
+
+//逐个解析共享字符串，count 为<sst>的uniqueCount(没有时为count)，每个<si>按顺序调用一次fn
+//富文本由多个<r><t>组成，拼接为一个字符串，忽略<rPh>中的注音；fn 返回错误时中断
+func scanSharedStrings(shareString *zip.File, count func(n int), fn func(s string) error) error {
+	if shareString == nil {
+		return nil
+	}
+	rc, err := shareString.Open()
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
 	d := xml.NewDecoder(rc)
 	var valueFlag int
-	index := 0
+	var inPhonetic bool
+	var text []byte
 
-loop:
 	for {
-		t, _ := d.Token()
-		if t == nil {
-			break
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
 		}
 		switch token := t.(type) {
 		case xml.StartElement:
 			name := token.Name.Local
 			if name == "sst" {
-				var count, uniqueCount int = 0, -1
+				var n, uniqueCount int = 0, -1
 				for _, v := range token.Attr {
 					if v.Name.Local == "count" {
-						count, _ = strconv.Atoi(v.Value)
+						n, _ = strconv.Atoi(v.Value)
 					}
 					if v.Name.Local == "uniqueCount" {
 						uniqueCount, _ = strconv.Atoi(v.Value)
@@ -503,26 +538,36 @@ loop:
 					}
 				}
 				if uniqueCount == -1 {
-					uniqueCount = count
+					uniqueCount = n
 				}
-				this.stringCache = make([]string, uniqueCount)
+				count(uniqueCount)
 			} else if name == "si" {
 				valueFlag = 1
+				text = text[:0]
+			} else if name == "t" && valueFlag == 1 && !inPhonetic {
+				valueFlag = 3
+			} else if name == "rPh" {
+				inPhonetic = true
 			}
 		case xml.EndElement:
 			name := token.Name.Local
 			if name == "si" {
 				valueFlag = 2
-				println(this.stringCache[index])
-				index++
+				if err = fn(string(text)); err != nil {
+					return err
+				}
+			} else if name == "t" && valueFlag == 3 {
+				valueFlag = 1
+			} else if name == "rPh" {
+				inPhonetic = false
 			} else if name == "sst" {
-				break loop
+				return nil
 			}
 		case xml.CharData:
-			if valueFlag == 1 {
-				this.stringCache[index] = this.stringCache[index] + string([]byte(token))
+			//富文本由多个<r><t>组成
+			if valueFlag == 3 {
+				text = append(text, token...)
 			}
 		}
 	}
-	return nil
 }
@@ -1,11 +1,16 @@
 package xlsx_reader
 
 import (
+	"bytes"
 	"fmt"
 	"math"
+	"reflect"
 	"runtime"
 	"strconv"
 	"testing"
+	"testing/fstest"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
 )
 
 func TestReader_ReadExlsFast(t *testing.T) {
@@ -168,3 +173,124 @@ func BenchmarkReader_decodeString(b *testing.B) {
 		r.decodeString()
 	}
 }
+
+func TestReader_ReaderFromSources(t *testing.T) {
+	data := simpleFixture.bytes(t)
+	fsys := fstest.MapFS{"files/members.xlsx": &fstest.MapFile{Data: data}}
+	readers := map[string]*reader{
+		"bytes":    ReaderFromBytes(data, "", true),
+		"readerAt": ReaderFromReaderAt(bytes.NewReader(data), int64(len(data)), "Sheet1", true),
+		"fs":       ReaderFromFS(fsys, "files/members.xlsx", "", true),
+	}
+	for name, r := range readers {
+		cols, err := r.Open()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(cols, []string{"姓名", "年龄"}) {
+			t.Errorf("%s: cols=%v", name, cols)
+		}
+		rows := fetchAll(t, r)
+		if !reflect.DeepEqual(rows, [][]string{{"张三", "18"}}) {
+			t.Errorf("%s: rows=%v", name, rows)
+		}
+		if err = r.Close(); err != nil {
+			t.Errorf("%s: close %v", name, err)
+		}
+	}
+}
+
+func TestReader_ReaderFromSourcesInvalid(t *testing.T) {
+	if _, err := ReaderFromBytes([]byte("not a zip"), "", true).Open(); err != ErrFileType {
+		t.Errorf("bytes: err=%v", err)
+	}
+	fsys := fstest.MapFS{"a.csv": &fstest.MapFile{Data: []byte("a,b")}}
+	if _, err := ReaderFromFS(fsys, "a.csv", "", true).Open(); err != ErrFileType {
+		t.Errorf("fs: err=%v", err)
+	}
+	if err := ReaderFromBytes(nil, "", true).FetchRow(func([]string) error { return nil }); err != ErrNotOpen {
+		t.Errorf("fetch before open: err=%v", err)
+	}
+}
+
+func TestReader_SetRowWidth(t *testing.T) {
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", head: `<dimension ref="A1:D3"/>`, sheetData: `<row r="1"><c r="A1"><v>1</v></c><c r="C1"><v>3</v></c></row>` +
+		`<row r="2"><c r="A2"><v>1</v></c></row><row r="3"><c r="E3"><v>5</v></c></row>`}}}
+	cases := map[int][][]string{
+		WidthAuto:      {{"1", "", "3"}, {"1"}, {"", "", "", "", "5"}},
+		WidthDimension: {{"1", "", "3", ""}, {"1", "", "", ""}, {"", "", "", ""}},
+		WidthHeader:    {{"1", "", "3"}, {"1", "", ""}, {"", "", ""}},
+		2:              {{"1", ""}, {"1", ""}, {"", ""}},
+	}
+	for width, want := range cases {
+		r := ReaderFromBytes(f.bytes(t), "", false)
+		r.SetRowWidth(width)
+		if _, err := r.Open(); err != nil {
+			t.Fatal(err)
+		}
+		if rows := fetchAll(t, r); !reflect.DeepEqual(rows, want) {
+			t.Errorf("width %d: rows=%q", width, rows)
+		}
+		r.Close()
+	}
+	r := ReaderFromBytes(f.bytes(t), "", false)
+	r.SetRowWidth(WidthDimension)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for r.Next() {
+		if len(r.Cells()) != 4 {
+			t.Errorf("cells=%v", r.Cells())
+		}
+	}
+}
+
+func TestReader_SetRange(t *testing.T) {
+	f := fixture{strings: []string{"报表", "姓名", "年龄", "备注"}, sheets: []fixtureSheet{{name: "Sheet1"},
+		{name: "My Sheet", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>3</v></c><c r="B3" t="s"><v>1</v></c><c r="C3" t="s"><v>2</v></c><c r="D3"><v>9</v></c></row>` +
+			`<row r="4"><c r="A4"><v>x</v></c><c r="B4" t="inlineStr"><is><t>张三</t></is></c><c r="C4"><v>30</v></c></row>` +
+			`<row r="6"><c r="C6"><v>40</v></c></row>` +
+			`<row r="7"><c r="B7" t="inlineStr"><is><t>李四</t></is></c></row>`}}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	if err := r.SetRange("'My Sheet'!B3:C6"); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	cols, err := r.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cols, []string{"姓名", "年龄"}) {
+		t.Errorf("cols=%q", cols)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"张三", "30"}, {"", "40"}}) {
+		t.Errorf("rows=%q", rows)
+	}
+
+	r = ReaderFromBytes(f.bytes(t), "My Sheet", false)
+	r.SetRangeRef(cellref.Range{Start: cellref.Ref{Col: 1, Row: 2}, End: cellref.Ref{Col: 2, Row: 6}})
+	r.SetFillBlankRows(true)
+	r.SetRowWidth(WidthDimension)
+	defer r.Close()
+	if _, err = r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var numbers []int
+	var cells [][]Cell
+	for r.Next() {
+		numbers = append(numbers, r.RowNumber())
+		cells = append(cells, r.Cells())
+	}
+	if !reflect.DeepEqual(numbers, []int{2, 3, 4, 5, 6}) {
+		t.Errorf("numbers=%v", numbers)
+	}
+	if len(cells[1]) != 2 || cells[1][0].Col != 1 || cells[1][0].Raw != "姓名" || cells[3] != nil && len(cells[3]) != 0 {
+		t.Errorf("cells=%v", cells)
+	}
+
+	if err = ReaderFromBytes(f.bytes(t), "", false).SetRange("B3:"); err == nil {
+		t.Error("invalid range accepted")
+	}
+}
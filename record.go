@@ -0,0 +1,138 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//按列名访问的一行，列的顺序同Open、OpenAndValidCols 返回的列
+//同一reader 的所有Record 共用列名及列名索引
+type Record struct {
+	reader *reader
+	cells  []Cell
+}
+
+//Record 的列名索引，重复的列名对应第一个
+func (this *reader) recordIndex() map[string]int {
+	if this.colIndex == nil {
+		this.colIndex = make(map[string]int, len(this.cols))
+		for i := len(this.cols) - 1; i >= 0; i-- {
+			this.colIndex[this.cols[i]] = i
+		}
+	}
+	return this.colIndex
+}
+
+//当前行作为Record，firstRowIsCol 必须为true
+func (this *reader) Record() Record {
+	return Record{reader: this, cells: this.Cells()}
+}
+
+//逐行读取Record，firstRowIsCol 必须为true，如果rowAction中返回 err!=nil 则中断
+func (this *reader) FetchRecord(rowAction func(rec Record) error) error {
+	if !this.firstRowIsCol {
+		return errors.New("firstRowIsCol must be true")
+	}
+	for this.Next() {
+		if err := rowAction(this.Record()); err != nil {
+			return err
+		}
+	}
+	return this.Err()
+}
+
+//列名，与Values 一一对应，不能修改
+func (this Record) Keys() []string {
+	return this.reader.cols
+}
+
+//单元格的文本，同FetchRow 中的row
+func (this Record) Values() []string {
+	values := make([]string, len(this.cells))
+	for i, c := range this.cells {
+		values[i] = this.reader.cellText(c)
+	}
+	return values
+}
+
+func (this Record) Len() int {
+	return len(this.cells)
+}
+
+//转换为map，重复的列名取第一个
+func (this Record) Map() map[string]string {
+	m := make(map[string]string, len(this.cells))
+	for i := len(this.cells) - 1; i >= 0; i-- {
+		m[this.reader.cols[i]] = this.reader.cellText(this.cells[i])
+	}
+	return m
+}
+
+//列对应的单元格，没有该列时ok 为false
+func (this Record) Cell(name string) (c Cell, ok bool) {
+	i, ok := this.reader.recordIndex()[name]
+	if !ok {
+		return
+	}
+	return this.cells[i], true
+}
+
+//列对应的文本，没有该列时为空字符串
+func (this Record) Get(name string) string {
+	s, _ := this.Lookup(name)
+	return s
+}
+
+//列对应的文本，没有该列时ok 为false
+func (this Record) Lookup(name string) (string, bool) {
+	c, ok := this.Cell(name)
+	if !ok {
+		return "", false
+	}
+	return this.reader.cellText(c), true
+}
+
+//整数，没有该列时为HeaderError，单元格为空或转换失败时为带单元格引用的CellError，空单元格的Err 为ErrRequired
+func (this Record) GetInt(name string) (int64, error) {
+	var v int64
+	err := this.get(name, &v)
+	return v, err
+}
+
+//浮点数，错误同GetInt
+func (this Record) GetFloat(name string) (float64, error) {
+	var v float64
+	err := this.get(name, &v)
+	return v, err
+}
+
+//布尔值，支持TRUE/FALSE、1/0、是/否、yes/no 等，错误同GetInt
+func (this Record) GetBool(name string) (bool, error) {
+	var v bool
+	err := this.get(name, &v)
+	return v, err
+}
+
+//日期单元格、Excel 序列值或常见的文本日期，错误同GetInt
+func (this Record) GetTime(name string) (time.Time, error) {
+	var v time.Time
+	err := this.get(name, &v)
+	return v, err
+}
+
+//与FetchStruct 使用相同的转换规则
+func (this Record) get(name string, v interface{}) error {
+	c, ok := this.Cell(name)
+	if !ok {
+		return &HeaderError{Missing: []string{name}}
+	}
+	if c.Kind == KindEmpty || (c.Kind == KindString && strings.TrimSpace(c.Raw) == "") {
+		return newCellError(c, name, ErrRequired)
+	}
+	if err := this.reader.setField(reflect.ValueOf(v).Elem(), c); err != nil {
+		return newCellError(c, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReader_FetchRecord(t *testing.T) {
+	r := ReaderFromBytes(memberFixture.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var recs []Record
+	if err := r.FetchRecord(func(rec Record) error {
+		recs = append(recs, rec)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("records=%d", len(recs))
+	}
+	rec := recs[0]
+	if rec.Get("*手机号码") != "13800138000" || rec.Get("不存在") != "" || rec.Len() != 8 {
+		t.Errorf("record=%q", rec.Values())
+	}
+	if m := rec.Map(); m["会员昵称"] != "张三" || len(m) != 8 {
+		t.Errorf("map=%v", m)
+	}
+	if !reflect.DeepEqual(rec.Keys(), r.cols) {
+		t.Errorf("keys=%q", rec.Keys())
+	}
+	if n, err := rec.GetInt("积分"); err != nil || n != 100 {
+		t.Errorf("GetInt=%d %v", n, err)
+	}
+	if f, err := rec.GetFloat("折扣"); err != nil || f != 0.85 {
+		t.Errorf("GetFloat=%v %v", f, err)
+	}
+	if b, err := rec.GetBool("VIP"); err != nil || !b {
+		t.Errorf("GetBool=%v %v", b, err)
+	}
+	if d, err := rec.GetTime("出生日期"); err != nil || !d.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTime=%v %v", d, err)
+	}
+
+	rec = recs[1]
+	var ce *CellError
+	if _, err := rec.GetInt("积分"); !errors.As(err, &ce) || ce.Ref != "E3" {
+		t.Errorf("GetInt=%v", err)
+	}
+	if _, err := rec.GetTime("出生日期"); !errors.Is(err, ErrRequired) || !errors.As(err, &ce) || ce.Ref != "D3" {
+		t.Errorf("GetTime=%v", err)
+	}
+	if _, err := rec.GetBool("不存在"); !errors.Is(err, ErrCols) {
+		t.Errorf("GetBool=%v", err)
+	}
+}
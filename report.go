@@ -0,0 +1,742 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+const (
+	ReportColumn    = "错误原因"     //错误报告中追加的列名
+	ReportFillColor = "FFFFC7CE" //错误单元格的填充颜色，ARGB
+
+	reportAuthor   = "xlsx-reader"
+	relsNamespace  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	commentsType   = relsNamespace + "/comments"
+	vmlDrawingType = relsNamespace + "/vmlDrawing"
+	commentsRelID  = "rIdXlsxReaderComments"
+	vmlRelID       = "rIdXlsxReaderVml"
+)
+
+//worksheet 中位于legacyDrawing 之后的元素
+var afterLegacyDrawing = map[string]bool{
+	"legacyDrawingHF": true, "drawingHF": true, "picture": true, "oleObjects": true,
+	"controls": true, "webPublishItems": true, "tableParts": true, "extLst": true,
+}
+
+//错误单元格的批注
+type reportComment struct {
+	row, col int
+	text     string
+}
+
+//生成错误报告的状态
+type report struct {
+	r  *reader
+	zw *zip.Writer
+
+	cells   map[int]map[int][]string //行号、列序号对应的错误信息
+	rows    map[int][]string         //行号对应的错误原因列的内容
+	rowNums []int                    //有错误的行号，从小到大
+	errCol  int                      //错误原因列的列序号
+
+	styled     bool        //有styles.xml 时填充错误单元格
+	baseXf     int         //原cellXfs 中的样式数量，新样式从该序号开始
+	styleMap   map[int]int //原样式序号对应的新样式序号
+	styleOrder []int       //按新样式序号排列的原样式序号
+
+	sheetRels    string //工作表关系文件的路径
+	withComments bool   //工作表中已有批注时不再添加批注
+	comments     []reportComment
+	commentsPath string
+	vmlPath      string
+	vmlID        int    //VML 绘图的idmap 序号，形状id 从vmlID*1024+1 开始
+	prefix       string //工作表中元素的命名空间前缀
+}
+
+//将当前工作表的错误报告写入w：复制整个xlsx 文件，在当前工作表中填充错误单元格、添加批注，
+//并在最后追加"错误原因"列，其他工作表保持不变；逐个标记读写工作表，不会将整个工作表读入内存
+//errs 一般为Violations 或FetchStruct 中的RowError，Sheet 不为空且不是当前工作表的错误被忽略
+//需在Open 之后调用，可以在读取过程中或读取完成后调用
+func (this *reader) WriteErrorReport(w io.Writer, errs []*CellError) error {
+	if this.sheetData == nil {
+		return ErrNotOpen
+	}
+	rep := &report{
+		r:        this,
+		zw:       zip.NewWriter(w),
+		cells:    make(map[int]map[int][]string),
+		rows:     make(map[int][]string),
+		styled:   this.workbook.styleSheet != nil && this.styles != nil,
+		styleMap: make(map[int]int),
+	}
+	if this.styles != nil {
+		rep.baseXf = len(this.styles.xfs)
+	}
+	maxCol, err := rep.maxColumn()
+	if err != nil {
+		return err
+	}
+	rep.index(errs, maxCol)
+	dir, base := path.Split(this.sheetData.Name)
+	rep.sheetRels = dir + "_rels/" + base + ".rels"
+	rep.withComments = true
+	if f := this.workbook.file(rep.sheetRels); f != nil {
+		has, err := hasRelationship(f, commentsType)
+		if err != nil {
+			return err
+		}
+		rep.withComments = !has
+	}
+	rep.commentsPath = this.workbook.unusedName("xl/comments%d.xml")
+	rep.vmlPath = this.workbook.unusedName("xl/drawings/vmlDrawing%d.vml")
+	if rep.withComments {
+		if rep.vmlID, err = this.workbook.unusedVmlID(); err != nil {
+			return err
+		}
+	}
+	if err := rep.write(); err != nil {
+		return err
+	}
+	return rep.zw.Close()
+}
+
+//工作表中最大的列序号，dimension 可能缺失或过时，需逐个单元格查找，没有单元格时为-1
+func (this *report) maxColumn() (int, error) {
+	rc, err := this.r.sheetData.Open()
+	if err != nil {
+		return -1, err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	maxCol, prevCol := -1, -1
+	inRow := false
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return maxCol, nil
+		}
+		if err != nil {
+			return -1, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				inRow, prevCol = true, -1
+			case "c":
+				if !inRow {
+					break
+				}
+				col := prevCol + 1
+				if v, ok := attrValue(t.Attr, "r"); ok {
+					if ref, err := cellref.ParseA1(v); err == nil {
+						col = ref.Col
+					}
+				}
+				prevCol = col
+				if col > maxCol {
+					maxCol = col
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "row" {
+				inRow = false
+			}
+		}
+	}
+}
+
+//按单元格及行整理错误信息，错误原因列位于maxCol 及所有错误单元格之后
+func (this *report) index(errs []*CellError, maxCol int) {
+	if n := this.r.colOffset() + len(this.r.cols) - 1; n > maxCol {
+		maxCol = n
+	}
+	for _, e := range errs {
+		if e == nil || e.Row <= 0 || (e.Sheet != "" && e.Sheet != this.r.sheet) {
+			continue
+		}
+		msg := fmt.Sprint(e.Err)
+		if e.Column != "" {
+			msg = e.Column + ": " + msg
+		}
+		if _, ok := this.rows[e.Row]; !ok {
+			this.rowNums = append(this.rowNums, e.Row)
+		}
+		this.rows[e.Row] = append(this.rows[e.Row], msg)
+		if e.Col >= 0 {
+			if this.cells[e.Row] == nil {
+				this.cells[e.Row] = make(map[int][]string)
+			}
+			this.cells[e.Row][e.Col] = append(this.cells[e.Row][e.Col], msg)
+			if e.Col > maxCol {
+				maxCol = e.Col
+			}
+		}
+	}
+	sort.Ints(this.rowNums)
+	this.errCol = maxCol + 1
+}
+
+func (this *report) write() error {
+	if err := this.writeSheet(); err != nil {
+		return err
+	}
+	relsFound := false
+	for _, f := range this.r.workbook.reader.File {
+		var err error
+		switch f.Name {
+		case this.r.sheetData.Name:
+			continue
+		case this.sheetRels:
+			relsFound = true
+			err = this.rewrite(f, this.writeRels)
+		case "[Content_Types].xml":
+			err = this.rewrite(f, this.writeContentTypes)
+		default:
+			if this.r.workbook.styleSheet == f && len(this.styleOrder) > 0 {
+				err = this.rewrite(f, this.writeStyles)
+			} else {
+				err = copyZipFile(this.zw, f)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(this.comments) == 0 {
+		return nil
+	}
+	if !relsFound {
+		fw, err := this.zw.Create(this.sheetRels)
+		if err != nil {
+			return err
+		}
+		out := newXmlWriter(fw)
+		out.raw(xml.Header)
+		out.start("Relationships", attr("xmlns", "http://schemas.openxmlformats.org/package/2006/relationships"))
+		this.writeRelationships(out)
+		out.end("Relationships")
+		if err = out.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := this.writeComments(); err != nil {
+		return err
+	}
+	return this.writeVml()
+}
+
+//原样复制压缩包中的文件，不解压，压缩数据及文件头(修改时间、压缩方式、注释等)保持不变
+func copyZipFile(zw *zip.Writer, f *zip.File) error {
+	rc, err := f.OpenRaw()
+	if err != nil {
+		return err
+	}
+	header := f.FileHeader
+	fw, err := zw.CreateRaw(&header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, rc)
+	return err
+}
+
+//逐个标记读取f，由fn 写入修改后的内容
+func (this *report) rewrite(f *zip.File, fn func(dec *xml.Decoder, out *xmlWriter) error) error {
+	return rewriteZipFile(this.zw, f, fn)
+}
+
+//逐个标记读取f，由fn 将修改后的内容写入zw 中的同名文件
+func rewriteZipFile(zw *zip.Writer, f *zip.File, fn func(dec *xml.Decoder, out *xmlWriter) error) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+	if err != nil {
+		return err
+	}
+	out := newXmlWriter(fw)
+	if err = fn(xml.NewDecoder(rc), out); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+//VML 绘图中的idmap，如<o:idmap v:ext="edit" data="1,2"/>
+var vmlIdmapReg = regexp.MustCompile(`<o:idmap\s[^>]*data="([^"]*)"`)
+
+//其他VML 绘图未使用的idmap 序号，idmap 在整个工作簿中不能重复，否则形状id 冲突
+//VML 不一定是完整的xml，只查找idmap
+func (this *Workbook) unusedVmlID() (int, error) {
+	id := 1
+	for _, f := range this.reader.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".vml") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return 0, err
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range vmlIdmapReg.FindAllSubmatch(b, -1) {
+			for _, v := range strings.Split(string(m[1]), ",") {
+				if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= id {
+					id = n + 1
+				}
+			}
+		}
+	}
+	return id, nil
+}
+
+//压缩包中不存在的文件名，pattern 中的%d 从1开始
+func (this *Workbook) unusedName(pattern string) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf(pattern, i)
+		if this.file(name) == nil {
+			return name
+		}
+	}
+}
+
+//关系文件中是否有typ 类型的关系
+func hasRelationship(f *zip.File, typ string) (bool, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return false, err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if t, ok := tok.(xml.StartElement); ok && t.Name.Local == "Relationship" {
+			if v, _ := attrValue(t.Attr, "Type"); v == typ {
+				return true, nil
+			}
+		}
+	}
+}
+
+//带工作表命名空间前缀的元素名
+func (this *report) name(local string) string {
+	if this.prefix == "" {
+		return local
+	}
+	return this.prefix + ":" + local
+}
+
+//错误单元格的新样式序号，没有styles.xml 时为-1
+func (this *report) style(orig int) int {
+	if !this.styled {
+		return -1
+	}
+	if s, ok := this.styleMap[orig]; ok {
+		return s
+	}
+	s := this.baseXf + len(this.styleOrder)
+	this.styleMap[orig] = s
+	this.styleOrder = append(this.styleOrder, orig)
+	return s
+}
+
+func (this *report) writeSheet() error {
+	f := this.r.sheetData
+	return this.rewrite(f, func(dec *xml.Decoder, out *xmlWriter) error {
+		var rowNum, prevCol, depth, next int
+		var pending []int //当前行中尚未输出的错误单元格
+		var inRow, hasLegacy, inserted bool
+		insertLegacy := func() {
+			if inserted || hasLegacy || !this.withComments || len(this.comments) == 0 {
+				return
+			}
+			inserted = true
+			out.element(this.name("legacyDrawing"),
+				xml.Attr{Name: xml.Name{Space: "xmlns", Local: "r"}, Value: relsNamespace},
+				xml.Attr{Name: xml.Name{Space: "r", Local: "id"}, Value: vmlRelID})
+		}
+		for {
+			tok, err := dec.RawToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				depth++
+				switch t.Name.Local {
+				case "worksheet":
+					this.prefix = t.Name.Space
+				case "dimension":
+					if v, ok := attrValue(t.Attr, "ref"); ok {
+						if d, err := cellref.ParseRange(v); err == nil && d.End.Col < this.errCol {
+							d.End.Col = this.errCol
+							t.Attr = setAttr(t.Attr, "ref", d.String())
+						}
+					}
+				case "row":
+					inRow = true
+					rowNum++
+					if v, ok := attrValue(t.Attr, "r"); ok {
+						if n, err := strconv.Atoi(v); err == nil {
+							rowNum = n
+						}
+					}
+					//工作表中没有的错误行
+					for ; next < len(this.rowNums) && this.rowNums[next] <= rowNum; next++ {
+						if this.rowNums[next] < rowNum {
+							this.writeRow(out, this.rowNums[next])
+						}
+					}
+					t.Attr = removeAttr(setAttr(t.Attr, "r", strconv.Itoa(rowNum)), "spans")
+					pending = this.errorCols(rowNum)
+					prevCol = -1
+				case "c":
+					if !inRow {
+						break
+					}
+					col := prevCol + 1
+					if v, ok := attrValue(t.Attr, "r"); ok {
+						if ref, err := cellref.ParseA1(v); err == nil {
+							col = ref.Col
+						}
+					}
+					prevCol = col
+					for len(pending) > 0 && pending[0] < col {
+						this.writeCell(out, rowNum, pending[0])
+						pending = pending[1:]
+					}
+					//补充的单元格带有r 属性，之后的单元格也需要r 属性
+					t.Attr = setAttr(t.Attr, "r", cellref.Ref{Col: col, Row: rowNum}.String())
+					if len(pending) > 0 && pending[0] == col {
+						pending = pending[1:]
+						orig := 0
+						if v, ok := attrValue(t.Attr, "s"); ok {
+							orig, _ = strconv.Atoi(v)
+						}
+						if s := this.style(orig); s >= 0 {
+							t.Attr = setAttr(t.Attr, "s", strconv.Itoa(s))
+						}
+						this.addComment(rowNum, col)
+					}
+				case "legacyDrawing":
+					hasLegacy = true
+				default:
+					if depth == 2 && afterLegacyDrawing[t.Name.Local] {
+						insertLegacy()
+					}
+				}
+				out.writeToken(t)
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "row":
+					if inRow {
+						for _, col := range pending {
+							this.writeCell(out, rowNum, col)
+						}
+						pending = nil
+						this.writeReason(out, rowNum)
+						inRow = false
+					}
+				case "sheetData":
+					for ; next < len(this.rowNums); next++ {
+						this.writeRow(out, this.rowNums[next])
+					}
+				case "worksheet":
+					insertLegacy()
+				}
+				out.writeToken(t)
+				depth--
+			default:
+				out.writeToken(tok)
+			}
+		}
+		if !inserted {
+			this.comments = nil
+		}
+		return nil
+	})
+}
+
+//行中有错误的列序号，从小到大
+func (this *report) errorCols(rowNum int) []int {
+	cols := make([]int, 0, len(this.cells[rowNum]))
+	for col := range this.cells[rowNum] {
+		cols = append(cols, col)
+	}
+	sort.Ints(cols)
+	return cols
+}
+
+//输出工作表中不存在的错误单元格
+func (this *report) writeCell(out *xmlWriter, rowNum, col int) {
+	attrs := []xml.Attr{attr("r", cellref.Ref{Col: col, Row: rowNum}.String())}
+	if s := this.style(0); s >= 0 {
+		attrs = append(attrs, attr("s", strconv.Itoa(s)))
+	}
+	out.element(this.name("c"), attrs...)
+	this.addComment(rowNum, col)
+}
+
+//输出工作表中不存在的错误行
+func (this *report) writeRow(out *xmlWriter, rowNum int) {
+	out.start(this.name("row"), attr("r", strconv.Itoa(rowNum)))
+	for _, col := range this.errorCols(rowNum) {
+		this.writeCell(out, rowNum, col)
+	}
+	this.writeReason(out, rowNum)
+	out.end(this.name("row"))
+}
+
+//在行的最后输出错误原因列，列名行输出ReportColumn
+func (this *report) writeReason(out *xmlWriter, rowNum int) {
+	var text string
+	if rowNum == this.r.headerRowNum && this.r.firstRowIsCol {
+		text = ReportColumn
+	} else if msgs := this.rows[rowNum]; len(msgs) > 0 {
+		text = strings.Join(msgs, "; ")
+	} else {
+		return
+	}
+	out.start(this.name("c"), attr("r", cellref.Ref{Col: this.errCol, Row: rowNum}.String()), attr("t", "inlineStr"))
+	out.start(this.name("is"))
+	out.textElement(this.name("t"), escapeCellText(text))
+	out.end(this.name("is"))
+	out.end(this.name("c"))
+}
+
+func (this *report) addComment(rowNum, col int) {
+	if this.withComments {
+		this.comments = append(this.comments, reportComment{row: rowNum, col: col, text: strings.Join(this.cells[rowNum][col], "\n")})
+	}
+}
+
+//在fills 中追加填充颜色，在cellXfs 中为每个错误单元格的原样式追加使用该填充的副本
+func (this *report) writeStyles(dec *xml.Decoder, out *xmlWriter) error {
+	var depth, fillsDepth, xfsDepth, fillCount int
+	var xfs [][]xml.Token //cellXfs 中每个xf 的标记
+	var capture []xml.Token
+	capturing := false
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		tok = xml.CopyToken(tok)
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && fillsDepth == 0 && afterFills[t.Name.Local] {
+				//没有<fills>时添加，前两个为必需的默认填充
+				p := elementPrefix(t.Name.Space)
+				out.raw(`<` + p + `fills count="3"><` + p + `fill><` + p + `patternFill patternType="none"/></` + p + `fill><` + p + `fill><` +
+					p + `patternFill patternType="gray125"/></` + p + `fill>` + reportFill(p) + `</` + p + `fills>`)
+				fillsDepth, fillCount = -1, 2
+			}
+			switch {
+			case t.Name.Local == "fills" && fillsDepth == 0:
+				fillsDepth = depth
+				t.Attr = incAttr(t.Attr, "count", 1)
+			case t.Name.Local == "fill" && depth == fillsDepth+1:
+				fillCount++
+			case t.Name.Local == "cellXfs" && xfsDepth == 0:
+				xfsDepth = depth
+				t.Attr = incAttr(t.Attr, "count", len(this.styleOrder))
+			case t.Name.Local == "xf" && xfsDepth > 0 && depth == xfsDepth+1:
+				capturing, capture = true, nil
+			}
+			tok = t
+		case xml.EndElement:
+			switch {
+			case depth == fillsDepth && t.Name.Local == "fills":
+				out.raw(reportFill(elementPrefix(t.Name.Space)))
+				fillsDepth = -1
+			case depth == xfsDepth && t.Name.Local == "cellXfs":
+				for _, orig := range this.styleOrder {
+					var src []xml.Token
+					if orig < len(xfs) {
+						src = xfs[orig]
+					} else {
+						src = []xml.Token{xml.StartElement{Name: xml.Name{Space: t.Name.Space, Local: "xf"}, Attr: []xml.Attr{attr("numFmtId", "0"), attr("fontId", "0"), attr("borderId", "0"), attr("xfId", "0")}}, xml.EndElement{Name: xml.Name{Space: t.Name.Space, Local: "xf"}}}
+					}
+					for i, x := range src {
+						if i == 0 {
+							start := x.(xml.StartElement)
+							start.Attr = setAttr(setAttr(start.Attr, "fillId", strconv.Itoa(fillCount)), "applyFill", "1")
+							x = start
+						}
+						out.writeToken(x)
+					}
+				}
+				xfsDepth = -1
+			}
+			depth--
+		}
+		if capturing {
+			capture = append(capture, tok)
+			if e, ok := tok.(xml.EndElement); ok && e.Name.Local == "xf" && depth == xfsDepth {
+				xfs = append(xfs, capture)
+				capturing = false
+			}
+		}
+		out.writeToken(tok)
+	}
+}
+
+//样式表中排在<fills>之后的元素
+var afterFills = map[string]bool{"borders": true, "cellStyleXfs": true, "cellXfs": true, "cellStyles": true,
+	"dxfs": true, "tableStyles": true, "colors": true, "extLst": true}
+
+//元素名的命名空间前缀，如"x:"，没有前缀时为空
+func elementPrefix(space string) string {
+	if space == "" {
+		return ""
+	}
+	return space + ":"
+}
+
+//错误单元格的填充
+func reportFill(p string) string {
+	return `<` + p + `fill><` + p + `patternFill patternType="solid"><` + p + `fgColor rgb="` + ReportFillColor +
+		`"/><` + p + `bgColor indexed="64"/></` + p + `patternFill></` + p + `fill>`
+}
+
+//属性值加n，没有该属性时不变
+func incAttr(attrs []xml.Attr, name string, n int) []xml.Attr {
+	if v, ok := attrValue(attrs, name); ok {
+		if c, err := strconv.Atoi(v); err == nil {
+			return setAttr(attrs, name, strconv.Itoa(c+n))
+		}
+	}
+	return attrs
+}
+
+//工作表关系文件中添加批注及VML 的关系
+func (this *report) writeRels(dec *xml.Decoder, out *xmlWriter) error {
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t, ok := tok.(xml.EndElement); ok && t.Name.Local == "Relationships" && len(this.comments) > 0 {
+			this.writeRelationships(out)
+		}
+		out.writeToken(tok)
+	}
+}
+
+func (this *report) writeRelationships(out *xmlWriter) {
+	dir := path.Dir(this.r.sheetData.Name)
+	up := strings.Repeat("../", strings.Count(dir, "/")+1)
+	out.element("Relationship", attr("Id", commentsRelID), attr("Type", commentsType), attr("Target", up+this.commentsPath))
+	out.element("Relationship", attr("Id", vmlRelID), attr("Type", vmlDrawingType), attr("Target", up+this.vmlPath))
+}
+
+//[Content_Types].xml 中添加批注及VML 的类型
+func (this *report) writeContentTypes(dec *xml.Decoder, out *xmlWriter) error {
+	hasVml := false
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if v, _ := attrValue(t.Attr, "Extension"); t.Name.Local == "Default" && strings.EqualFold(v, "vml") {
+				hasVml = true
+			}
+		case xml.EndElement:
+			if t.Name.Local == "Types" && len(this.comments) > 0 {
+				if !hasVml {
+					out.element("Default", attr("Extension", "vml"), attr("ContentType", "application/vnd.openxmlformats-officedocument.vmlDrawing"))
+				}
+				out.element("Override", attr("PartName", "/"+this.commentsPath), attr("ContentType", "application/vnd.openxmlformats-officedocument.spreadsheetml.comments+xml"))
+			}
+		}
+		out.writeToken(tok)
+	}
+}
+
+func (this *report) writeComments() error {
+	fw, err := this.zw.Create(this.commentsPath)
+	if err != nil {
+		return err
+	}
+	out := newXmlWriter(fw)
+	out.raw(xml.Header)
+	out.start("comments", attr("xmlns", "http://schemas.openxmlformats.org/spreadsheetml/2006/main"))
+	out.start("authors")
+	out.textElement("author", reportAuthor)
+	out.end("authors")
+	out.start("commentList")
+	for _, c := range this.comments {
+		out.start("comment", attr("ref", cellref.Ref{Col: c.col, Row: c.row}.String()), attr("authorId", "0"))
+		out.start("text")
+		out.textElement("t", escapeCellText(c.text), attr("xml:space", "preserve"))
+		out.end("text")
+		out.end("comment")
+	}
+	out.end("commentList")
+	out.end("comments")
+	return out.Flush()
+}
+
+//批注的显示需要VML 绘图
+func (this *report) writeVml() error {
+	fw, err := this.zw.Create(this.vmlPath)
+	if err != nil {
+		return err
+	}
+	//每个idmap 序号对应1024 个形状id
+	ids := make([]string, (len(this.comments)+1023)/1024)
+	for i := range ids {
+		ids[i] = strconv.Itoa(this.vmlID + i)
+	}
+	out := newXmlWriter(fw)
+	out.raw(`<xml xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:x="urn:schemas-microsoft-com:office:excel">` +
+		`<o:shapelayout v:ext="edit"><o:idmap v:ext="edit" data="` + strings.Join(ids, ",") + `"/></o:shapelayout>` +
+		`<v:shapetype id="_x0000_t202" coordsize="21600,21600" o:spt="202" path="m,l,21600r21600,l21600,xe">` +
+		`<v:stroke joinstyle="miter"/><v:path gradientshapeok="t" o:connecttype="rect"/></v:shapetype>`)
+	for i, c := range this.comments {
+		row := c.row - 1
+		out.raw(fmt.Sprintf(`<v:shape id="_x0000_s%d" type="#_x0000_t202" style="position:absolute;margin-left:59.25pt;margin-top:1.5pt;width:108pt;height:59.25pt;z-index:%d;visibility:hidden" fillcolor="#ffffe1" o:insetmode="auto">`+
+			`<v:fill color2="#ffffe1"/><v:shadow on="t" color="black" obscured="t"/><v:path o:connecttype="none"/>`+
+			`<v:textbox style="mso-direction-alt:auto"><div style="text-align:left"></div></v:textbox>`+
+			`<x:ClientData ObjectType="Note"><x:MoveWithCells/><x:SizeWithCells/><x:Anchor>%d, 15, %d, 2, %d, 15, %d, 4</x:Anchor>`+
+			`<x:AutoFill>False</x:AutoFill><x:Row>%d</x:Row><x:Column>%d</x:Column></x:ClientData></v:shape>`,
+			this.vmlID*1024+1+i, i+1, c.col+1, row, c.col+3, row+4, row, c.col))
+	}
+	out.raw(`</xml>`)
+	return out.Flush()
+}
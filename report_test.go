@@ -0,0 +1,177 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//读取压缩包中的全部文件，并检查xml 是否完整
+func unzipAll(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(b)
+		dec := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err = dec.Token(); err != nil {
+				break
+			}
+		}
+		if err != io.EOF {
+			t.Errorf("%s: %v", f.Name, err)
+		}
+	}
+	return files
+}
+
+func TestReader_WriteErrorReport(t *testing.T) {
+	f := validateFixture
+	f.files = map[string]string{
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0"><alignment horizontal="center"/></xf></cellXfs>` +
+			`</styleSheet>`,
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="xml" ContentType="application/xml"/></Types>`,
+	}
+	f.sheets = append([]fixtureSheet(nil), f.sheets...)
+	f.sheets[0].head = `<dimension ref="A1:F4"/>`
+	f.sheets[0].sheetData = strings.Replace(f.sheets[0].sheetData, `<c r="D3">`, `<c r="D3" s="1">`, 1)
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(validateSpec()); err != nil {
+		t.Fatal(err)
+	}
+	fetchAll(t, r)
+	var buf bytes.Buffer
+	if err := r.WriteErrorReport(&buf, r.Violations()); err != nil {
+		t.Fatal(err)
+	}
+	files := unzipAll(t, buf.Bytes())
+
+	out := ReaderFromBytes(buf.Bytes(), "", true)
+	defer out.Close()
+	cols, err := out.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols[len(cols)-1] != ReportColumn || len(cols) != 7 {
+		t.Errorf("cols=%q", cols)
+	}
+	var reasons []string
+	var styles [][]int
+	for out.Next() {
+		cells := out.Cells()
+		reasons = append(reasons, cells[6].Raw)
+		var s []int
+		for _, c := range out.currentCells() {
+			s = append(s, c.style)
+		}
+		styles = append(styles, s)
+	}
+	if reasons[0] != "" || !strings.HasPrefix(reasons[1], "手机号码: ") || strings.Count(reasons[1], ";") != 4 {
+		t.Errorf("reasons=%q", reasons)
+	}
+	//原样式0、1 的错误单元格使用新样式2、3，没有值的B3 补充为空单元格
+	if !reflect.DeepEqual(styles[1], []int{2, 2, 3, 2, 0}) || !reflect.DeepEqual(styles[2], []int{2, 2, 0, 2, 0}) ||
+		!strings.Contains(files["xl/worksheets/sheet1.xml"], `<c r="B3" s="2"/>`) {
+		t.Errorf("styles=%v", styles)
+	}
+	if !strings.Contains(files["xl/styles.xml"], `<fills count="3">`) || !strings.Contains(files["xl/styles.xml"], `<cellXfs count="4">`) ||
+		!strings.Contains(files["xl/styles.xml"], `<xf numFmtId="14" fontId="0" fillId="2" borderId="0" applyFill="1"><alignment horizontal="center"/></xf></cellXfs>`) {
+		t.Errorf("styles.xml=%s", files["xl/styles.xml"])
+	}
+	if !strings.Contains(files["xl/worksheets/sheet1.xml"], `<dimension ref="A1:G4"/>`) || !strings.Contains(files["xl/worksheets/sheet1.xml"], `r:id="rIdXlsxReaderVml"/></worksheet>`) {
+		t.Errorf("sheet1.xml=%s", files["xl/worksheets/sheet1.xml"])
+	}
+	if strings.Count(files["xl/comments1.xml"], "<comment ") != 8 || !strings.Contains(files["xl/comments1.xml"], `ref="B3"`) {
+		t.Errorf("comments1.xml=%s", files["xl/comments1.xml"])
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `PartName="/xl/comments1.xml"`) || !strings.Contains(files["xl/worksheets/_rels/sheet1.xml.rels"], `Target="../../xl/drawings/vmlDrawing1.vml"`) {
+		t.Errorf("content types=%s rels=%s", files["[Content_Types].xml"], files["xl/worksheets/_rels/sheet1.xml.rels"])
+	}
+	if files["xl/workbook.xml"] == "" || files["xl/drawings/vmlDrawing1.vml"] == "" {
+		t.Errorf("files=%d", len(files))
+	}
+}
+
+func TestReader_WriteErrorReportNoFills(t *testing.T) {
+	f := validateFixture
+	f.files = map[string]string{
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="1"><font><sz val="11"/></font></fonts>` +
+			`<cellXfs count="1"><xf numFmtId="0" fontId="0" borderId="0"/></cellXfs></styleSheet>`,
+	}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(validateSpec()); err != nil {
+		t.Fatal(err)
+	}
+	fetchAll(t, r)
+	var buf bytes.Buffer
+	if err := r.WriteErrorReport(&buf, r.Violations()); err != nil {
+		t.Fatal(err)
+	}
+	//没有<fills>时添加两个默认填充及错误填充，错误样式使用第3个填充
+	styles := unzipAll(t, buf.Bytes())["xl/styles.xml"]
+	if !strings.Contains(styles, `</fonts><fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`+
+		`<fill><patternFill patternType="solid"><fgColor rgb="`+ReportFillColor+`"/>`) ||
+		!strings.Contains(styles, `<xf numFmtId="0" fontId="0" borderId="0" fillId="2" applyFill="1"/></cellXfs>`) {
+		t.Errorf("styles.xml=%s", styles)
+	}
+}
+
+func TestReader_WriteErrorReportStaleDimension(t *testing.T) {
+	//dimension 过时，第2行的D2 在列名之外
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", head: `<dimension ref="A1"/>`, sheetData: `<row r="1">` +
+		`<c r="A1" t="inlineStr"><is><t>a</t></is></c><c r="B1" t="inlineStr"><is><t>b</t></is></c></row>` +
+		`<row r="2"><c r="A2"><v>1</v></c><c r="B2"><v>2</v></c><c r="D2"><v>4</v></c></row>`}},
+		//其他工作表批注的VML 绘图
+		files: map[string]string{"xl/drawings/vmlDrawing1.vml": `<xml xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office">` +
+			`<o:shapelayout v:ext="edit"><o:idmap v:ext="edit" data="1"/></o:shapelayout><v:shape id="_x0000_s1025"/></xml>`}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if _, err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	errs := []*CellError{{Ref: "A2", Row: 2, Col: 0, Column: "a", Err: errors.New("错误")}}
+	if err := r.WriteErrorReport(&buf, errs); err != nil {
+		t.Fatal(err)
+	}
+	files := unzipAll(t, buf.Bytes())
+	if !strings.Contains(files["xl/worksheets/sheet1.xml"], `<c r="D2"><v>4</v></c><c r="E2" t="inlineStr"><is><t>a: 错误</t></is></c></row>`) {
+		t.Errorf("sheet1.xml=%s", files["xl/worksheets/sheet1.xml"])
+	}
+	out := ReaderFromBytes(buf.Bytes(), "", true)
+	defer out.Close()
+	cols, err := out.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := fetchAll(t, out); len(cols) != 5 || cols[4] != ReportColumn || rows[0][3] != "4" || rows[0][4] != "a: 错误" {
+		t.Errorf("cols=%q rows=%q", cols, rows)
+	}
+	vml := files["xl/drawings/vmlDrawing2.vml"]
+	if !strings.Contains(vml, `<o:idmap v:ext="edit" data="2"/>`) || !strings.Contains(vml, `id="_x0000_s2049"`) {
+		t.Errorf("vmlDrawing2.vml=%s", vml)
+	}
+}
@@ -0,0 +1,154 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+)
+
+const DefaultStringCacheSize = 4096 //Indexed 策略默认缓存的字符串数量
+
+//Indexed 策略的共享字符串：只解析一次，字符串依次写入解压后的临时文件，
+//每个字符串的偏移量以8 字节定长写入另一个临时文件，查找时按序号直接定位读取，
+//内存中只保存最近使用的cacheSize 个字符串
+type stringIndex struct {
+	data   *os.File //全部字符串依次拼接
+	offset *os.File //第i 个字符串在data 中的起止位置为第i、i+1 个偏移量
+	count  int      //字符串数量
+
+	closeMu sync.RWMutex //读取时持有读锁，Close 持有写锁
+	closed  bool
+
+	mu        sync.Mutex
+	cacheSize int
+	lru       *list.List            //最近使用的在前
+	cache     map[int]*list.Element //序号对应lru 中的元素
+}
+
+type cachedString struct {
+	index int
+	value string
+}
+
+//解析shareString 并写入dir 目录下的临时文件，dir 为空时使用os.TempDir
+func newStringIndex(shareString *zip.File, dir string, cacheSize int) (*stringIndex, error) {
+	if cacheSize <= 0 {
+		cacheSize = DefaultStringCacheSize
+	}
+	this := &stringIndex{cacheSize: cacheSize, lru: list.New(), cache: make(map[int]*list.Element)}
+	var err error
+	if this.data, err = os.CreateTemp(dir, "xlsx-strings-*"); err != nil {
+		return nil, err
+	}
+	if this.offset, err = os.CreateTemp(dir, "xlsx-offsets-*"); err != nil {
+		this.Close()
+		return nil, err
+	}
+	data, offset := bufio.NewWriter(this.data), bufio.NewWriter(this.offset)
+	var pos int64
+	var buf [8]byte
+	writeOffset := func() error {
+		binary.LittleEndian.PutUint64(buf[:], uint64(pos))
+		_, err := offset.Write(buf[:])
+		return err
+	}
+	err = scanSharedStrings(shareString, func(n int) {}, func(s string) error {
+		if err := writeOffset(); err != nil {
+			return err
+		}
+		n, err := data.WriteString(s)
+		pos += int64(n)
+		this.count++
+		return err
+	})
+	if err == nil {
+		err = writeOffset()
+	}
+	if err == nil {
+		err = data.Flush()
+	}
+	if err == nil {
+		err = offset.Flush()
+	}
+	if err != nil {
+		this.Close()
+		return nil, err
+	}
+	return this, nil
+}
+
+//第i 个字符串，超出范围、读取失败或已关闭时返回空字符串
+func (this *stringIndex) get(i int) string {
+	if i < 0 || i >= this.count {
+		return ""
+	}
+	this.closeMu.RLock()
+	defer this.closeMu.RUnlock()
+	if this.closed {
+		return ""
+	}
+	this.mu.Lock()
+	if e, ok := this.cache[i]; ok {
+		this.lru.MoveToFront(e)
+		this.mu.Unlock()
+		return e.Value.(*cachedString).value
+	}
+	this.mu.Unlock()
+	s, err := this.read(i)
+	if err != nil {
+		return ""
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if _, ok := this.cache[i]; !ok {
+		this.cache[i] = this.lru.PushFront(&cachedString{index: i, value: s})
+		if this.lru.Len() > this.cacheSize {
+			last := this.lru.Back()
+			this.lru.Remove(last)
+			delete(this.cache, last.Value.(*cachedString).index)
+		}
+	}
+	return s
+}
+
+//从临时文件读取第i 个字符串，ReadAt 可以并发调用
+func (this *stringIndex) read(i int) (string, error) {
+	var buf [16]byte
+	if _, err := this.offset.ReadAt(buf[:], int64(i)*8); err != nil {
+		return "", err
+	}
+	start := int64(binary.LittleEndian.Uint64(buf[:8]))
+	end := int64(binary.LittleEndian.Uint64(buf[8:]))
+	b := make([]byte, end-start)
+	if _, err := this.data.ReadAt(b, start); err != nil && err != io.EOF {
+		return "", err
+	}
+	return string(b), nil
+}
+
+//关闭并删除临时文件，等待正在进行的读取结束，可以重复调用
+func (this *stringIndex) Close() error {
+	this.closeMu.Lock()
+	defer this.closeMu.Unlock()
+	if this.closed {
+		return nil
+	}
+	this.closed = true
+	var err error
+	for _, f := range []*os.File{this.data, this.offset} {
+		if f == nil {
+			continue
+		}
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+		if e := os.Remove(f.Name()); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package xlsx_reader
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func indexedFixture(n int) fixture {
+	var sheetData strings.Builder
+	strs := make([]string, n)
+	for i := range strs {
+		strs[i] = "字符串" + strconv.Itoa(i)
+	}
+	//倒序引用，LowMemery 策略每次都要从头查找
+	for i := n - 1; i >= 0; i-- {
+		row := strconv.Itoa(n - i)
+		sheetData.WriteString(`<row r="` + row + `"><c r="A` + row + `" t="s"><v>` + strconv.Itoa(i) + `</v></c></row>`)
+	}
+	return fixture{sheets: []fixtureSheet{{name: "Sheet1", sheetData: sheetData.String()}}, strings: strs}
+}
+
+func TestReader_IndexedPolicy(t *testing.T) {
+	f := indexedFixture(50)
+	//富文本及注音
+	f.strings[3] = `</t></si><si><r><t>富</t></r><r><t>文本</t></r><rPh><t>ふ</t></rPh></si><si><t>`
+	f.strings[4] = ""
+	data := f.bytes(t)
+	read := func(policy Policy) [][]string {
+		r := ReaderFromBytes(data, "", false)
+		r.SetPolicy(policy)
+		defer r.Close()
+		if _, err := r.Open(); err != nil {
+			t.Fatal(err)
+		}
+		return fetchAll(t, r)
+	}
+	want := read(Fast)
+	if got := read(Indexed); !reflect.DeepEqual(got, want) {
+		t.Errorf("Indexed=%q\nFast=%q", got, want)
+	}
+	if want[len(want)-1][0] != "字符串0" || want[len(want)-5][0] != "富文本" {
+		t.Errorf("rows=%q", want)
+	}
+}
+
+func TestWorkbook_IndexedCache(t *testing.T) {
+	wb, err := OpenWorkbookFromBytes(indexedFixture(100).bytes(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb.SetPolicy(Indexed)
+	wb.SetStringCacheSize(8)
+	r, err := wb.SheetReader("", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	rows := fetchAll(t, r)
+	r.Close()
+	if len(rows) != 100 || rows[0][0] != "字符串99" || rows[99][0] != "字符串0" {
+		t.Errorf("rows=%q", rows)
+	}
+	index := wb.stringIndex
+	if index.count != 100 || index.lru.Len() != 8 || len(index.cache) != 8 {
+		t.Errorf("count=%d lru=%d cache=%d", index.count, index.lru.Len(), len(index.cache))
+	}
+	if index.get(100) != "" || index.get(-1) != "" {
+		t.Error("out of range index should be empty")
+	}
+	names := []string{index.data.Name(), index.offset.Name()}
+	if err = wb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if index.get(0) != "" {
+		t.Error("closed index should be empty")
+	}
+	for _, name := range names {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", name, err)
+		}
+	}
+}
+
+func TestReader_BrokenSharedStrings(t *testing.T) {
+	f := indexedFixture(3)
+	f.strings = nil
+	//截断的sharedStrings.xml
+	f.files = map[string]string{"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="3"><si><t>字符串0</t></si><si><t>字符`}
+	data := f.bytes(t)
+	for _, policy := range []Policy{Fast, Indexed} {
+		r := ReaderFromBytes(data, "", false)
+		r.SetPolicy(policy)
+		if _, err := r.Open(); err == nil {
+			t.Errorf("policy %d: expected error", policy)
+		}
+		r.Close()
+	}
+}
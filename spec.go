@@ -0,0 +1,287 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+//列名的规范化规则，可以组合使用
+type Normalize int
+
+const (
+	NormTrim  = Normalize(1 << iota) //去掉首尾空白
+	NormFold                         //不区分大小写
+	NormWidth                        //全角字母、数字、符号及空格转换为半角
+	NormStar                         //去掉模板中标记必填列的前导*，如"*手机号码"
+
+	NormAll = NormTrim | NormFold | NormWidth | NormStar
+)
+
+//规范化列名
+func (this Normalize) apply(s string) string {
+	if this&NormWidth != 0 {
+		s = toHalfWidth(s)
+	}
+	if this&NormTrim != 0 {
+		s = strings.TrimSpace(s)
+	}
+	if this&NormStar != 0 {
+		s = strings.TrimLeft(s, "*")
+		if this&NormTrim != 0 {
+			s = strings.TrimSpace(s)
+		}
+	}
+	if this&NormFold != 0 {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+//全角字符(U+FF01~U+FF5E)及全角空格转换为半角
+func toHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xfee0
+		}
+		return r
+	}, s)
+}
+
+//逻辑列
+type Column struct {
+	Name      string                //规范的列名，FetchRow 等按该名称输出，结构体标签也使用该名称
+	Aliases   []string              //其他可接受的列名，如"手机号码"的别名"手机"
+	Optional  bool                  //可选列，工作表中没有该列时值为空，否则返回ErrCols
+	Rules     []Rule                //读取时对每个单元格的校验，见Violations
+	Transform func(s string) string //校验之前对单元格文本的规范化，如NormalizeMobile，FetchRow 等输出规范化后的文本
+	Unique    bool                  //整个工作表中该列的值不能重复，空值除外
+	Enum      []string              //可选值，读取时校验同OneOf，生成的模板中为下拉列表
+	Date      bool                  //日期列，生成的模板中该列使用日期格式
+	Comment   string                //列的填写说明，写入模板的说明工作表
+}
+
+//列名的匹配规则
+type HeaderSpec struct {
+	Columns   []Column
+	Normalize Normalize //比较前对工作表的列名及Column 中的名称进行规范化，0 为完全相等
+	RowRules  []RowRule //读取时对每行的校验，用于多列之间的校验
+}
+
+//由列名构造HeaderSpec，所有列都是必须的，使用全部规范化规则
+func NewHeaderSpec(cols ...string) HeaderSpec {
+	spec := HeaderSpec{Columns: make([]Column, len(cols)), Normalize: NormAll}
+	for i, c := range cols {
+		spec.Columns[i] = Column{Name: c}
+	}
+	return spec
+}
+
+//规范化后的列名及别名
+func (this HeaderSpec) names(col Column) []string {
+	names := make([]string, 0, len(col.Aliases)+1)
+	names = append(names, this.Normalize.apply(col.Name))
+	for _, a := range col.Aliases {
+		names = append(names, this.Normalize.apply(a))
+	}
+	return names
+}
+
+//在工作表的列名中查找每个逻辑列的位置，没有找到的列为-1
+//已匹配的列不会再被其他逻辑列匹配，missing 为没有找到的必须列
+func (this HeaderSpec) match(header []string) (index []int, missing []string) {
+	normalized := make([]string, len(header))
+	for i, h := range header {
+		normalized[i] = this.Normalize.apply(h)
+	}
+	used := make([]bool, len(header))
+	index = make([]int, len(this.Columns))
+	for i, col := range this.Columns {
+		index[i] = -1
+	search:
+		for _, name := range this.names(col) {
+			for j, h := range normalized {
+				if !used[j] && h == name {
+					index[i] = j
+					used[j] = true
+					break search
+				}
+			}
+		}
+		if index[i] == -1 && !col.Optional {
+			missing = append(missing, col.Name)
+		}
+	}
+	return
+}
+
+//打开要读取的工作表，并按spec 匹配列名，firstRowIsCol 参数必须为true
+//匹配后FetchRow 中的行按spec.Columns 的顺序输出，Open 返回的列名为Column.Name
+func (this *reader) OpenAndValidSpec(spec HeaderSpec) error {
+	if !this.firstRowIsCol {
+		return errors.New("firstRowIsCol must be true")
+	}
+	this.headerSpecs = []HeaderSpec{spec}
+	if _, err := this.Open(); err != nil {
+		return err
+	}
+	return this.checkSpec(spec)
+}
+
+//按spec 建立工作表列序号与输出位置的对应关系
+func (this *reader) checkSpec(spec HeaderSpec) error {
+	index, missing := spec.match(this.cols)
+	if len(missing) > 0 {
+		return newHeaderError(spec, this.cols, index, missing, this.colOffset())
+	}
+	this.applySpec(spec, index)
+	return nil
+}
+
+//index:spec.match 的结果
+func (this *reader) applySpec(spec HeaderSpec, index []int) {
+	off := this.colOffset()
+	this.columnMaps = make(map[int]int, len(index))
+	this.maxIndex = -1
+	cols := make([]string, len(spec.Columns))
+	for i, j := range index {
+		cols[i] = spec.Columns[i].Name
+		if j < 0 {
+			continue
+		}
+		this.columnMaps[off+j] = i
+		if this.maxIndex < off+j {
+			this.maxIndex = off + j
+		}
+	}
+	this.cols = cols
+	this.colIndex = nil
+	this.spec = &spec
+}
+
+//列名不匹配的详细信息，errors.Is(err, ErrCols) 为true
+type HeaderError struct {
+	Missing     []string          //没有找到的必须列
+	Extra       []string          //工作表中没有匹配任何列的列名
+	Duplicates  []string          //工作表中重复的列名(规范化后相同)
+	Blank       []string          //列名为空的列，如"C"
+	Suggestions map[string]string //缺少的列与工作表中最接近的列名，按编辑距离计算
+}
+
+func (this *HeaderError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrCols.Error())
+	if len(this.Missing) > 0 {
+		b.WriteString("; missing:")
+		for _, m := range this.Missing {
+			fmt.Fprintf(&b, " %q", m)
+			if s, ok := this.Suggestions[m]; ok {
+				fmt.Fprintf(&b, " (did you mean %q?)", s)
+			}
+		}
+	}
+	if len(this.Extra) > 0 {
+		fmt.Fprintf(&b, "; extra: %q", this.Extra)
+	}
+	if len(this.Duplicates) > 0 {
+		fmt.Fprintf(&b, "; duplicate: %q", this.Duplicates)
+	}
+	if len(this.Blank) > 0 {
+		fmt.Fprintf(&b, "; blank columns: %s", strings.Join(this.Blank, ","))
+	}
+	return b.String()
+}
+
+func (this *HeaderError) Unwrap() error {
+	return ErrCols
+}
+
+//header:工作表的列名，index:spec.match 的结果，off:header 第一列的列序号
+func newHeaderError(spec HeaderSpec, header []string, index []int, missing []string, off int) *HeaderError {
+	this := &HeaderError{Missing: missing, Suggestions: make(map[string]string)}
+	matched := make([]bool, len(header))
+	for _, j := range index {
+		if j >= 0 {
+			matched[j] = true
+		}
+	}
+	seen := make(map[string]bool, len(header))
+	var extra []int
+	for j, h := range header {
+		n := spec.Normalize.apply(h)
+		if strings.TrimSpace(h) == "" {
+			this.Blank = append(this.Blank, cellref.ColumnName(off+j))
+			continue
+		}
+		if seen[n] {
+			this.Duplicates = append(this.Duplicates, h)
+		}
+		seen[n] = true
+		if !matched[j] {
+			this.Extra = append(this.Extra, h)
+			extra = append(extra, j)
+		}
+	}
+	//在未匹配的列中为缺少的列查找编辑距离不超过名称长度一半的最接近的列名
+	for i, col := range spec.Columns {
+		if index[i] >= 0 || col.Optional {
+			continue
+		}
+		best, bestDist := -1, 0
+		for _, name := range spec.names(col) {
+			for _, j := range extra {
+				d := editDistance(name, spec.Normalize.apply(header[j]))
+				if best == -1 || d < bestDist {
+					best, bestDist = j, d
+				}
+			}
+			limit := len([]rune(name)) / 2
+			if limit < 1 {
+				limit = 1
+			}
+			if best >= 0 && bestDist <= limit {
+				this.Suggestions[col.Name] = header[best]
+				break
+			}
+			best = -1
+		}
+	}
+	return this
+}
+
+//按字符计算的编辑距离(Levenshtein)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,98 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		" *手机号码 ":  "手机号码",
+		"＊ＩＤ　":     "id",
+		"Email":   "email",
+		"* 会员昵称": "会员昵称",
+	}
+	for in, want := range cases {
+		if got := NormAll.apply(in); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+	if got := (NormTrim).apply(" *A "); got != "*A" {
+		t.Errorf("trim only: %q", got)
+	}
+}
+
+func TestReader_OpenAndValidSpec(t *testing.T) {
+	f := fixture{strings: []string{"*手机 ", "会员昵称", "ＥＭＡＩＬ"}, sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>` +
+		`<row r="2"><c r="A2"><v>13800000000</v></c><c r="B2" t="inlineStr"><is><t>张三</t></is></c><c r="C2" t="inlineStr"><is><t>a@b.c</t></is></c></row>`}}}
+	spec := HeaderSpec{Normalize: NormAll, Columns: []Column{
+		{Name: "email"},
+		{Name: "手机号码", Aliases: []string{"手机"}},
+		{Name: "生日", Optional: true},
+	}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(spec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.cols, []string{"email", "手机号码", "生日"}) {
+		t.Errorf("cols=%q", r.cols)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"a@b.c", "13800000000", ""}}) {
+		t.Errorf("rows=%q", rows)
+	}
+
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidCols([]string{"会员昵称", "手机"}); err != nil {
+		t.Fatal(err)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"张三", "13800000000"}}) {
+		t.Errorf("rows=%q", rows)
+	}
+
+	spec.Normalize = 0
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(spec); !errors.Is(err, ErrCols) {
+		t.Errorf("exact match: err=%v", err)
+	}
+}
+
+func TestReader_HeaderError(t *testing.T) {
+	f := fixture{strings: []string{"手机号", "姓名", "姓名", "备注"}, sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c>` +
+		`<c r="C1" t="s"><v>2</v></c><c r="E1" t="s"><v>3</v></c></row>`}}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	err := r.OpenAndValidCols([]string{"手机号码", "姓名", "生日"})
+	if !errors.Is(err, ErrCols) {
+		t.Fatalf("err=%v", err)
+	}
+	var he *HeaderError
+	if !errors.As(err, &he) {
+		t.Fatalf("err=%T", err)
+	}
+	want := &HeaderError{
+		Missing:     []string{"手机号码", "生日"},
+		Extra:       []string{"手机号", "姓名", "备注"},
+		Duplicates:  []string{"姓名"},
+		Blank:       []string{"D"},
+		Suggestions: map[string]string{"手机号码": "手机号"},
+	}
+	if !reflect.DeepEqual(he, want) {
+		t.Errorf("got %+v", he)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{{"", "abc", 3}, {"kitten", "sitting", 3}, {"手机号码", "手机", 2}, {"姓名", "姓名", 0}}
+	for _, c := range cases {
+		if got := editDistance(c.a, c.b); got != c.want {
+			t.Errorf("%q %q: got %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
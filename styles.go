@@ -0,0 +1,205 @@
+package xlsx_reader
+
+import (
+	"strings"
+)
+
+//内置数字格式，5-8、14、22 等与区域设置相关的格式按中文系统取值
+var builtInNumFmt = map[int]string{
+	0:  "General",
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	5:  `"¥"#,##0;"¥"\-#,##0`,
+	6:  `"¥"#,##0;[Red]"¥"\-#,##0`,
+	7:  `"¥"#,##0.00;"¥"\-#,##0.00`,
+	8:  `"¥"#,##0.00;[Red]"¥"\-#,##0.00`,
+	9:  "0%",
+	10: "0.00%",
+	11: "0.00E+00",
+	12: "# ?/?",
+	13: "# ??/??",
+	14: "yyyy/m/d",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm AM/PM",
+	19: "h:mm:ss AM/PM",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "yyyy/m/d h:mm",
+	27: `yyyy"年"m"月"`,
+	28: `m"月"d"日"`,
+	29: `m"月"d"日"`,
+	30: "m-d-yy",
+	31: `yyyy"年"m"月"d"日"`,
+	32: `h"时"mm"分"`,
+	33: `h"时"mm"分"ss"秒"`,
+	34: `上午/下午h"时"mm"分"`,
+	35: `上午/下午h"时"mm"分"ss"秒"`,
+	36: `yyyy"年"m"月"`,
+	37: "#,##0 ;(#,##0)",
+	38: "#,##0 ;[Red](#,##0)",
+	39: "#,##0.00;(#,##0.00)",
+	40: "#,##0.00;[Red](#,##0.00)",
+	41: `_(* #,##0_);_(* \(#,##0\);_(* "-"_);_(@_)`,
+	42: `_("¥"* #,##0_);_("¥"* \(#,##0\);_("¥"* "-"_);_(@_)`,
+	43: `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`,
+	44: `_("¥"* #,##0.00_);_("¥"* \(#,##0.00\);_("¥"* "-"??_);_(@_)`,
+	45: "mm:ss",
+	46: "[h]:mm:ss",
+	47: "mmss.0",
+	48: "##0.0E+0",
+	49: "@",
+	50: `yyyy"年"m"月"`,
+	51: `m"月"d"日"`,
+	52: `yyyy"年"m"月"`,
+	53: `m"月"d"日"`,
+	54: `m"月"d"日"`,
+	55: `上午/下午h"时"mm"分"`,
+	56: `上午/下午h"时"mm"分"ss"秒"`,
+	57: `yyyy"年"m"月"`,
+	58: `m"月"d"日"`,
+}
+
+//内置的日期时间格式：14-22、45-47 以及中文区域的27-36、50-58
+func isDateFormatID(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+}
+
+//自定义格式中，除去引号内的文本、转义字符、[Red]、[>100]、[$-804] 等方括号内容及General 后，
+//包含y、m、d、h、s 任意一个则为日期时间格式，[h]、[mm]、[ss] 经过时间也视为时间格式，
+//单独的g、gg、ggg 及e、ee 为日本年号及年号年份(如ge.m.d)，aaa、aaaa 为中文星期，AM/PM、A/P 为上下午
+func isDateFormatCode(code string) bool {
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; c {
+		case '"':
+			if j := strings.IndexByte(code[i+1:], '"'); j > -1 {
+				i += j + 1
+			} else {
+				return false
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			j := strings.IndexByte(code[i:], ']')
+			if j < 0 {
+				return false
+			}
+			if strings.Trim(strings.ToLower(code[i+1:i+j]), "hms") == "" {
+				return true
+			}
+			i += j
+		case 'y', 'Y', 'm', 'M', 'd', 'D', 'h', 'H', 's', 'S':
+			return true
+		case 'g', 'G', 'e', 'E':
+			if n := generalLen(code[i:]); n > 0 {
+				i += n - 1
+				continue
+			}
+			//科学计数法，如0.00E+00
+			if (c == 'e' || c == 'E') && i+1 < len(code) && (code[i+1] == '+' || code[i+1] == '-') {
+				return false
+			}
+			if (i == 0 || !isASCIILetter(code[i-1])) && eraLen(code[i:]) > 0 {
+				return true
+			}
+		case 'a', 'A':
+			lower := strings.ToLower(code[i:])
+			if strings.HasPrefix(lower, "aaa") || strings.HasPrefix(lower, "am/pm") || strings.HasPrefix(lower, "a/p") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//s 开头的General 格式的长度，不是时为0
+//包括中文Excel 及WPS 的G/通用格式、日文的G/標準、韩文的G/표준 等本地化的写法
+func generalLen(s string) int {
+	if len(s) >= 7 && strings.EqualFold(s[:7], "General") {
+		return 7
+	}
+	if len(s) > 2 && s[0]|0x20 == 'g' && s[1] == '/' {
+		j := 2
+		for j < len(s) && s[j] >= 0x80 {
+			j++
+		}
+		if j > 2 {
+			return j
+		}
+	}
+	return 0
+}
+
+//s 开头的日本年号标记g、gg、ggg 及年号年份e、ee 的总长度，如ge.m.d 中的ge，
+//后面紧跟其他字母时是普通文本，返回0
+func eraLen(s string) int {
+	g := 0
+	for g < len(s) && g < 3 && s[g]|0x20 == 'g' {
+		g++
+	}
+	n := g
+	for n < len(s) && n-g < 2 && s[n]|0x20 == 'e' {
+		n++
+	}
+	if n == 0 || (n < len(s) && isASCIILetter(s[n])) {
+		return 0
+	}
+	return n
+}
+
+func isASCIILetter(c byte) bool {
+	return c|0x20 >= 'a' && c|0x20 <= 'z'
+}
+
+//解析后的styles.xml
+type styles struct {
+	numFmts map[int]string //自定义数字格式
+	xfs     []int          //cellXfs 中每个样式的numFmtId
+	dates   []bool         //cellXfs 中每个样式是否为日期时间格式
+}
+
+func newStyles(sheet *xlsxStyleSheet) *styles {
+	this := &styles{
+		numFmts: make(map[int]string, len(sheet.NumFmts.NumFmt)),
+		xfs:     make([]int, len(sheet.CellXfs.Xf)),
+		dates:   make([]bool, len(sheet.CellXfs.Xf)),
+	}
+	for _, f := range sheet.NumFmts.NumFmt {
+		this.numFmts[f.NumFmtID] = f.FormatCode
+	}
+	for i, xf := range sheet.CellXfs.Xf {
+		this.xfs[i] = xf.NumFmtID
+		if code, ok := this.numFmts[xf.NumFmtID]; ok {
+			this.dates[i] = isDateFormatCode(code)
+		} else {
+			this.dates[i] = isDateFormatID(xf.NumFmtID)
+		}
+	}
+	return this
+}
+
+//单元格s 属性对应的数字格式，没有样式表或超出范围时为General
+func (this *styles) format(style int) string {
+	if this == nil || style < 0 || style >= len(this.xfs) {
+		return "General"
+	}
+	id := this.xfs[style]
+	if code, ok := this.numFmts[id]; ok {
+		return code
+	}
+	if code, ok := builtInNumFmt[id]; ok {
+		return code
+	}
+	return "General"
+}
+
+//单元格s 属性对应的样式是否为日期时间格式
+func (this *styles) isDate(style int) bool {
+	if this == nil || style < 0 || style >= len(this.dates) {
+		return false
+	}
+	return this.dates[style]
+}
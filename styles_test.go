@@ -0,0 +1,92 @@
+package xlsx_reader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsDateFormatCode(t *testing.T) {
+	cases := map[string]bool{
+		"General":                    false,
+		"0.00":                       false,
+		"0.00E+00":                   false,
+		`#,##0.00 "元"`:               false,
+		`0 "days"`:                   false,
+		"[Red]#,##0":                 false,
+		"@":                          false,
+		"yyyy-mm-dd":                 true,
+		`yyyy"年"m"月"d"日"`:            true,
+		"[$-804]yyyy/m/d h:mm":       true,
+		"[h]:mm":                     true,
+		"[H]":                        true,
+		`[Blue][<=100]0;\h\o\u\r`:    false,
+		"hh:mm:ss AM/PM;@":           true,
+		"[>=100][Magenta]#,##0;0.00": false,
+		"0.00e-00":                   false,
+		"ge.m.d":                     true,
+		`[$-411]ggge"年"m"月"d"日"`:     true,
+		"[$-411]ee/mm/dd":            true,
+		"aaaa":                       true,
+		`[$-804]aaa`:                 true,
+		"A/P":                        true,
+		`0.0 "a"`:                    false,
+		"General;-General":           false,
+		"G/通用格式":                     false,
+		"G/通用格式;[Red]-G/通用格式":        false,
+		`$#,##0.00 "USD"`:            false,
+		"[$EUR] #,##0.00":            false,
+		`#,##0.00\ \e\u\r`:           false,
+		"#,##0.00 Reg":               false,
+		"[$-411]gge":                 true,
+	}
+	for code, want := range cases {
+		if got := isDateFormatCode(code); got != want {
+			t.Errorf("%q: got %v want %v", code, got, want)
+		}
+	}
+}
+
+var dateFixture = fixture{
+	sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1">` +
+		`<c r="A1" s="1"><v>45352</v></c>` +
+		`<c r="B1" s="2"><v>45352.5</v></c>` +
+		`<c r="C1" s="3"><v>0.75</v></c>` +
+		`<c r="D1" s="4"><v>45352</v></c>` +
+		`<c r="E1" s="5"><v>45352</v></c>` +
+		`</row>`}},
+	files: map[string]string{"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="2"><numFmt numFmtId="176" formatCode="yyyy&quot;年&quot;m&quot;月&quot;d&quot;日&quot;"/><numFmt numFmtId="177" formatCode="#,##0.00_ "/></numFmts>` +
+		`<cellXfs count="6"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="22"/><xf numFmtId="21"/><xf numFmtId="176"/><xf numFmtId="177"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+func TestReader_DateCells(t *testing.T) {
+	check := func(f fixture, want []time.Time) {
+		r := ReaderFromBytes(f.bytes(t), "", false)
+		defer r.Close()
+		if _, err := r.Open(); err != nil {
+			t.Fatal(err)
+		}
+		err := r.FetchCells(func(cells []Cell) error {
+			for i, w := range want {
+				if v, ok := cells[i].Time(); !ok || cells[i].Kind != KindDate || !v.Equal(w) {
+					t.Errorf("cell %d=%+v want %v", i, cells[i], w)
+				}
+			}
+			if cells[4].Kind != KindNumber || cells[4].Value != float64(45352) {
+				t.Errorf("cell 4=%+v", cells[4])
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	check(dateFixture, []time.Time{day, day.Add(12 * time.Hour), time.Date(1899, 12, 30, 18, 0, 0, 0, time.UTC), day})
+
+	f := dateFixture
+	f.date1904 = true
+	day = day.AddDate(4, 0, 1)
+	check(f, []time.Time{day, day.Add(12 * time.Hour), time.Date(1904, 1, 1, 18, 0, 0, 0, time.UTC), day})
+}
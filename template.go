@@ -0,0 +1,284 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+const (
+	TemplateInstructionSheet = "填写说明" //生成的模板中说明工作表的名称
+
+	templateListSheet  = "_lists"               //隐藏的工作表，保存较长的下拉列表
+	templateNameKey    = "_xlsxreader.template" //隐藏的定义名称，记录模板名称
+	templateVersionKey = "_xlsxreader.version"  //隐藏的定义名称，记录模板版本
+)
+
+//已登记的模板，Name 用于区分不同版本的列布局
+type Template struct {
+	Name         string
+	Version      string //模板版本，与Name 一起写入生成的模板，见Workbook.TemplateVersion
+	Spec         HeaderSpec
+	Instructions []string //填写说明，生成模板时写入说明工作表，每项一行
+}
+
+//没有匹配的模板，Errors 与Templates 一一对应，errors.Is(err, ErrCols) 为true
+type TemplateError struct {
+	Templates []string
+	Errors    []*HeaderError
+}
+
+func (this *TemplateError) Error() string {
+	msgs := make([]string, len(this.Templates))
+	for i, name := range this.Templates {
+		msgs[i] = name + ": " + this.Errors[i].Error()
+	}
+	return "No template matches: " + strings.Join(msgs, " | ")
+}
+
+func (this *TemplateError) Unwrap() error {
+	return ErrCols
+}
+
+//打开要读取的工作表，只读取一次列名并与templates 逐个比较，firstRowIsCol 参数必须为true
+//包含全部必须列的模板中，名称及版本与文件中记录的相同(见TemplateVersion)的为最佳模板，
+//否则匹配列数最多、其次工作表中多余的列最少的为最佳模板，相同时取靠前的
+//返回最佳模板并按其列名输出，同OpenAndValidSpec；没有匹配的模板时返回TemplateError
+func (this *reader) OpenAndMatchTemplate(templates ...Template) (*Template, error) {
+	if !this.firstRowIsCol {
+		return nil, errors.New("firstRowIsCol must be true")
+	}
+	if len(templates) == 0 {
+		return nil, errors.New("No template")
+	}
+	this.headerSpecs = make([]HeaderSpec, len(templates))
+	for i, t := range templates {
+		this.headerSpecs[i] = t.Spec
+	}
+	if _, err := this.Open(); err != nil {
+		return nil, err
+	}
+	filled := 0 //非空列名的数量
+	for _, h := range this.cols {
+		if strings.TrimSpace(h) != "" {
+			filled++
+		}
+	}
+	best, bestMatched, bestExtra := -1, 0, 0
+	var bestIndex []int
+	report := &TemplateError{}
+	name, version := this.workbook.TemplateVersion()
+	marked := -1
+	for i, t := range templates {
+		index, missing := t.Spec.match(this.cols)
+		if len(missing) > 0 {
+			report.Templates = append(report.Templates, t.Name)
+			report.Errors = append(report.Errors, newHeaderError(t.Spec, this.cols, index, missing, this.colOffset()))
+			continue
+		}
+		matched := 0
+		for _, j := range index {
+			if j >= 0 {
+				matched++
+			}
+		}
+		extra := filled - matched
+		if name != "" && t.Name == name && t.Version == version && marked == -1 {
+			marked, bestIndex = i, index
+		}
+		if marked >= 0 {
+			continue
+		}
+		if best == -1 || matched > bestMatched || (matched == bestMatched && extra < bestExtra) {
+			best, bestMatched, bestExtra, bestIndex = i, matched, extra, index
+		}
+	}
+	if marked >= 0 {
+		best = marked
+	}
+	if best == -1 {
+		return nil, report
+	}
+	this.applySpec(templates[best].Spec, bestIndex)
+	return &templates[best], nil
+}
+
+//打开的文件由Template.Generate 生成时返回其中记录的模板名称及版本，需在Open 之后调用
+func (this *reader) TemplateVersion() (name, version string) {
+	if this.workbook == nil {
+		return "", ""
+	}
+	return this.workbook.TemplateVersion()
+}
+
+//生成空白的导入模板并写入w：
+//第一个工作表为列名行，必须列的列名为红色粗体，Normalize 包含NormStar 时加前导*；
+//Enum 列为下拉列表，Date 列为日期格式；之后是填写说明工作表，包含Instructions 及每列的说明；
+//模板名称及版本记录在隐藏的定义名称中，读取时见TemplateVersion
+func (this Template) Generate(w io.Writer) error {
+	xw := Writer(w)
+	name := this.Name
+	if xw.checkSheetName(name) != nil || name == TemplateInstructionSheet || name == templateListSheet {
+		name = "Sheet1"
+	}
+	if err := xw.AddSheet(name); err != nil {
+		return err
+	}
+	cols := this.Spec.Columns
+	header := make([]interface{}, len(cols))
+	widths := make([]float64, len(cols))
+	styles := make([]int, len(cols))
+	var lists [][]string //写入隐藏工作表的下拉列表
+	for i, col := range cols {
+		text, style := col.Name, styleHeader
+		if !col.Optional {
+			style = styleRequired
+			if this.Spec.Normalize&NormStar != 0 {
+				text = "*" + text
+			}
+		}
+		header[i] = styledValue{value: text, style: style}
+		widths[i] = textWidth(text) + 2
+		if col.Date {
+			styles[i] = styleDate
+			if widths[i] < 12 {
+				widths[i] = 12
+			}
+		}
+		for _, v := range col.Enum {
+			if w := textWidth(v) + 2; w > widths[i] {
+				widths[i] = w
+			}
+		}
+		if widths[i] < 10 {
+			widths[i] = 10
+		}
+		if widths[i] > 50 {
+			widths[i] = 50
+		}
+		if len(col.Enum) == 0 {
+			continue
+		}
+		ref := cellref.Range{Start: cellref.Ref{Col: i, Row: 2}, End: cellref.Ref{Col: i, Row: cellref.MaxRows}}.String()
+		formula, ok := listFormula(col.Enum)
+		if !ok {
+			rng := cellref.Range{Start: cellref.Ref{Col: len(lists), Row: 1, ColAbs: true, RowAbs: true},
+				End: cellref.Ref{Col: len(lists), Row: len(col.Enum), ColAbs: true, RowAbs: true}}
+			formula = quoteSheetName(templateListSheet) + "!" + rng.String()
+			lists = append(lists, col.Enum)
+		}
+		if err := xw.addValidation(ref, formula); err != nil {
+			return err
+		}
+	}
+	if err := xw.SetColWidths(widths...); err != nil {
+		return err
+	}
+	if err := xw.setColStyles(styles...); err != nil {
+		return err
+	}
+	if err := xw.SetFreezeRows(1); err != nil {
+		return err
+	}
+	if err := xw.WriteRow(header...); err != nil {
+		return err
+	}
+
+	if err := xw.AddSheet(TemplateInstructionSheet); err != nil {
+		return err
+	}
+	if err := xw.SetColWidths(20, 8, 16, 30, 50); err != nil {
+		return err
+	}
+	for _, line := range this.Instructions {
+		if err := xw.WriteRow(line); err != nil {
+			return err
+		}
+	}
+	if len(this.Instructions) > 0 {
+		if err := xw.WriteRow(); err != nil {
+			return err
+		}
+	}
+	titles := []string{"列名", "必填", "格式", "可选值", "说明"}
+	row := make([]interface{}, len(titles))
+	for i, t := range titles {
+		row[i] = styledValue{value: t, style: styleHeader}
+	}
+	if err := xw.WriteRow(row...); err != nil {
+		return err
+	}
+	for _, col := range cols {
+		required, format := "是", ""
+		if col.Optional {
+			required = "否"
+		}
+		if col.Date {
+			format = "日期，如2006-01-02"
+		}
+		if err := xw.WriteRow(col.Name, required, format, strings.Join(col.Enum, "、"), col.Comment); err != nil {
+			return err
+		}
+	}
+
+	if len(lists) > 0 {
+		if err := xw.AddSheet(templateListSheet); err != nil {
+			return err
+		}
+		if err := xw.SetSheetState("hidden"); err != nil {
+			return err
+		}
+		for r := 0; ; r++ {
+			row := make([]interface{}, len(lists))
+			more := false
+			for c, list := range lists {
+				if r < len(list) {
+					row[c], more = list[r], true
+				}
+			}
+			if !more {
+				break
+			}
+			if err := xw.WriteRow(row...); err != nil {
+				return err
+			}
+		}
+	}
+	if this.Name != "" || this.Version != "" {
+		if err := xw.DefineName(templateNameKey, quoteFormula(this.Name), true); err != nil {
+			return err
+		}
+		if err := xw.DefineName(templateVersionKey, quoteFormula(this.Version), true); err != nil {
+			return err
+		}
+	}
+	return xw.Close()
+}
+
+//列宽，中文等宽字符按2 个字符计算
+func textWidth(s string) float64 {
+	w := 0
+	for _, r := range s {
+		if utf8.RuneLen(r) > 1 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return float64(w)
+}
+
+//字符串常量公式，如"v2"
+func quoteFormula(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func unquoteFormula(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
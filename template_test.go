@@ -0,0 +1,115 @@
+package xlsx_reader
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func memberTemplates() []Template {
+	return []Template{
+		{Name: "v1", Spec: NewHeaderSpec("手机", "姓名")},
+		{Name: "v2", Spec: HeaderSpec{Normalize: NormAll, Columns: []Column{{Name: "手机号码", Aliases: []string{"手机"}}, {Name: "姓名"}, {Name: "生日", Optional: true}}}},
+		{Name: "v3", Spec: NewHeaderSpec("手机号码", "会员昵称", "积分")},
+	}
+}
+
+func TestReader_OpenAndMatchTemplate(t *testing.T) {
+	f := fixture{strings: []string{"*手机", "姓名", "生日"}, sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>` +
+		`<row r="2"><c r="A2"><v>13800000000</v></c><c r="B2" t="inlineStr"><is><t>张三</t></is></c><c r="C2" t="inlineStr"><is><t>2000-01-01</t></is></c></row>`}}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	tpl, err := r.OpenAndMatchTemplate(memberTemplates()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.Name != "v2" {
+		t.Errorf("template=%s", tpl.Name)
+	}
+	if rows := fetchAll(t, r); !reflect.DeepEqual(rows, [][]string{{"13800000000", "张三", "2000-01-01"}}) {
+		t.Errorf("rows=%q", rows)
+	}
+
+	f.strings = []string{"电话", "昵称", "生日"}
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	_, err = r.OpenAndMatchTemplate(memberTemplates()...)
+	var te *TemplateError
+	if !errors.Is(err, ErrCols) || !errors.As(err, &te) {
+		t.Fatalf("err=%v", err)
+	}
+	if !reflect.DeepEqual(te.Templates, []string{"v1", "v2", "v3"}) || len(te.Errors) != 3 {
+		t.Errorf("report=%+v", te)
+	}
+}
+
+func TestTemplate_Generate(t *testing.T) {
+	long := make([]string, 60)
+	for i := range long {
+		long[i] = "城市" + strconv.Itoa(i)
+	}
+	tpl := Template{Name: "会员导入", Version: "2", Instructions: []string{"带*的列为必填列"}, Spec: HeaderSpec{Normalize: NormAll, Columns: []Column{
+		{Name: "手机号码", Comment: "11位手机号码"},
+		{Name: "性别", Enum: []string{"男", "女"}},
+		{Name: "生日", Date: true, Optional: true},
+		{Name: "城市", Enum: long, Optional: true},
+	}}}
+	var buf bytes.Buffer
+	if err := tpl.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := unzipAll(t, buf.Bytes())
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, s := range []string{`<col min="3" max="3" width="12" customWidth="1" style="1"/>`, `state="frozen"`,
+		`<dataValidation type="list" allowBlank="1" showErrorMessage="1" sqref="B2:B1048576"><formula1>"男,女"</formula1></dataValidation>`,
+		`<formula1>_lists!$A$1:$A$60</formula1>`} {
+		if !strings.Contains(sheet, s) {
+			t.Errorf("sheet1.xml missing %s\n%s", s, sheet)
+		}
+	}
+
+	wb, err := OpenWorkbookFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	sheets := wb.Sheets()
+	if len(sheets) != 3 || sheets[0].Name != "会员导入" || sheets[1].Name != TemplateInstructionSheet || sheets[2].Visible() {
+		t.Errorf("sheets=%v", sheets)
+	}
+	r, _ := wb.SheetReaderAt(0, true)
+	defer r.Close()
+	cols, err := r.Open()
+	if err != nil || !reflect.DeepEqual(cols, []string{"*手机号码", "*性别", "生日", "城市"}) {
+		t.Errorf("cols=%q err=%v", cols, err)
+	}
+	if name, version := r.TemplateVersion(); name != "会员导入" || version != "2" {
+		t.Errorf("version=%q %q", name, version)
+	}
+	if styles := []int{r.Cells()[0].style, r.Cells()[2].style}; !reflect.DeepEqual(styles, []int{styleRequired, styleHeader}) {
+		t.Errorf("styles=%v", styles)
+	}
+
+	r, _ = wb.SheetReader(TemplateInstructionSheet, false)
+	defer r.Close()
+	r.Open()
+	var rows [][]string
+	for r.Next() {
+		rows = append(rows, r.Row())
+	}
+	if len(rows) != 7 || rows[0][0] != "带*的列为必填列" || strings.Join(rows[3], ",") != "手机号码,是,,,11位手机号码" || rows[4][3] != "男、女" || rows[5][2] == "" {
+		t.Errorf("instructions=%q", rows)
+	}
+
+	//名称及版本相同的模板优先，即使其他模板匹配的列更多
+	other := Template{Name: "完整", Spec: NewHeaderSpec("手机号码", "性别", "生日", "城市")}
+	r = ReaderFromBytes(buf.Bytes(), "", true)
+	defer r.Close()
+	matched, err := r.OpenAndMatchTemplate(other, tpl)
+	if err != nil || matched.Name != "会员导入" {
+		t.Errorf("matched=%v err=%v", matched, err)
+	}
+}
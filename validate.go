@@ -0,0 +1,215 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalid       = errors.New("Invalid value")
+	ErrDuplicate     = errors.New("Duplicate value")
+	ErrTooManyErrors = errors.New("Too many validation errors")
+)
+
+//单元格校验规则，返回nil 表示通过
+//除Required 外的内置规则对空单元格不做校验
+type Rule func(c Cell) error
+
+//行校验规则，用于多列之间的校验，返回*CellError 时可以指定列，否则错误属于整行
+type RowRule func(rec Record) error
+
+func isBlank(c Cell) bool {
+	return c.Kind == KindEmpty || strings.TrimSpace(c.Raw) == ""
+}
+
+//不能为空
+func Required() Rule {
+	return func(c Cell) error {
+		if isBlank(c) {
+			return ErrRequired
+		}
+		return nil
+	}
+}
+
+//文本需匹配正则表达式，如`^1\d{10}$`，expr 无效时panic
+func Pattern(expr string) Rule {
+	re := regexp.MustCompile(expr)
+	return func(c Cell) error {
+		if isBlank(c) || re.MatchString(c.Raw) {
+			return nil
+		}
+		return fmt.Errorf("%w: does not match %s", ErrInvalid, expr)
+	}
+}
+
+//文本长度(按字符)在min 与max 之间，max 小于等于0 时不限制最大长度
+func Length(min, max int) Rule {
+	return func(c Cell) error {
+		if isBlank(c) {
+			return nil
+		}
+		n := utf8.RuneCountInString(c.Raw)
+		if n < min || (max > 0 && n > max) {
+			return fmt.Errorf("%w: length %d out of range [%d,%d]", ErrInvalid, n, min, max)
+		}
+		return nil
+	}
+}
+
+//数值在min 与max 之间
+func Between(min, max float64) Rule {
+	return func(c Cell) error {
+		if isBlank(c) {
+			return nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(c.Raw), 64)
+		if err != nil {
+			return fmt.Errorf("%w: not a number", ErrInvalid)
+		}
+		if f < min || f > max {
+			return fmt.Errorf("%w: %v out of range [%v,%v]", ErrInvalid, f, min, max)
+		}
+		return nil
+	}
+}
+
+//文本为values 之一
+func OneOf(values ...string) Rule {
+	return func(c Cell) error {
+		if isBlank(c) {
+			return nil
+		}
+		for _, v := range values {
+			if c.Raw == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: must be one of %q", ErrInvalid, values)
+	}
+}
+
+//日期在from 与to 之间，零值为不限制
+//日期单元格、常见的文本日期及数值(按工作簿的日期系统，1900 或1904，解析为序列值)
+func DateBetween(from, to time.Time) Rule {
+	return func(c Cell) error {
+		if isBlank(c) {
+			return nil
+		}
+		t, ok := c.Time()
+		if !ok {
+			var err error
+			if f, ferr := strconv.ParseFloat(c.Raw, 64); ferr == nil && c.Kind == KindNumber {
+				t = GetExcelTime(f, c.date1904)
+			} else if t, err = parseTime(c.Raw); err != nil {
+				return fmt.Errorf("%w: not a date", ErrInvalid)
+			}
+		}
+		if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && t.After(to)) {
+			return fmt.Errorf("%w: date %s out of range", ErrInvalid, t.Format("2006-01-02"))
+		}
+		return nil
+	}
+}
+
+//读取到的错误达到n 个时停止读取，Err 返回ErrTooManyErrors，需在读取之前调用，0 为不限制
+func (this *reader) SetMaxErrors(n int) {
+	this.maxErrors = n
+}
+
+//已读取的行中全部的校验错误
+func (this *reader) Violations() []*CellError {
+	return this.violations
+}
+
+//当前行的校验错误，没有错误时为nil
+func (this *reader) RowViolations() RowError {
+	return this.rowViolations
+}
+
+//按HeaderSpec 中的Transform 规范化当前行后按规则校验，
+//错误数达到maxErrors 时仍输出当前行，下一次Next 返回false
+//没有任何值的行及没有任何规则的spec 不做校验
+func (this *reader) validate() {
+	this.rowViolations = nil
+	if !this.spec.hasRules() || len(this.currentCells()) == 0 {
+		return
+	}
+	for i, c := range this.rowCells {
+		if j, ok := this.columnMaps[c.Col]; ok && this.spec.Columns[j].Transform != nil {
+			this.rowCells[i].Raw = this.spec.Columns[j].Transform(c.Raw)
+		}
+	}
+	cells := this.Cells()
+	for i, col := range this.spec.Columns {
+		c := cells[i]
+		for _, rule := range col.Rules {
+			if err := rule(c); err != nil {
+				this.addViolation(newCellError(c, col.Name, err))
+			}
+		}
+		if len(col.Enum) > 0 {
+			if err := OneOf(col.Enum...)(c); err != nil {
+				this.addViolation(newCellError(c, col.Name, err))
+			}
+		}
+		if col.Unique && !isBlank(c) {
+			if this.uniques == nil {
+				this.uniques = make(map[int]map[string]int)
+			}
+			seen := this.uniques[i]
+			if seen == nil {
+				seen = make(map[string]int)
+				this.uniques[i] = seen
+			}
+			v := strings.TrimSpace(c.Raw)
+			if row, ok := seen[v]; ok {
+				this.addViolation(newCellError(c, col.Name, fmt.Errorf("%w: same as row %d", ErrDuplicate, row)))
+			} else {
+				seen[v] = c.Row
+			}
+		}
+	}
+	for _, rule := range this.spec.RowRules {
+		if err := rule(this.Record()); err != nil {
+			var ce *CellError
+			if !errors.As(err, &ce) {
+				ce = &CellError{Row: this.curRow, Col: -1, Err: err}
+			}
+			this.addViolation(ce)
+		}
+	}
+	if this.maxErrors > 0 && len(this.violations) >= this.maxErrors {
+		this.iterErr = ErrTooManyErrors
+	}
+}
+
+//是否有需要逐行执行的规范化或校验规则，spec 为nil 时返回false
+func (this *HeaderSpec) hasRules() bool {
+	if this == nil {
+		return false
+	}
+	if len(this.RowRules) > 0 {
+		return true
+	}
+	for _, col := range this.Columns {
+		if len(col.Rules) > 0 || len(col.Enum) > 0 || col.Unique || col.Transform != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *reader) addViolation(e *CellError) {
+	e.Sheet = this.sheet
+	if e.Row == 0 {
+		e.Row = this.curRow
+	}
+	this.rowViolations = append(this.rowViolations, e)
+	this.violations = append(this.violations, e)
+}
@@ -0,0 +1,185 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMobile = errors.New("Invalid mobile number")
+	ErrIDCard = errors.New("Invalid resident ID card number")
+	ErrUSCC   = errors.New("Invalid unified social credit code")
+)
+
+//去掉手机号码中的空格、短横线及+86/0086/86 前缀
+func NormalizeMobile(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '　' {
+			return -1
+		}
+		return r
+	}, toHalfWidth(strings.TrimSpace(s)))
+	for _, prefix := range []string{"+86", "0086"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	if len(s) == 13 && strings.HasPrefix(s, "86") {
+		s = s[2:]
+	}
+	return s
+}
+
+//中国大陆手机号码：1 开头，第二位3-9，共11位
+func IsMobile(s string) bool {
+	if len(s) != 11 || s[0] != '1' || s[1] < '3' || s[1] > '9' {
+		return false
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+//手机号码校验规则，校验前按NormalizeMobile 规范化
+func Mobile() Rule {
+	return func(c Cell) error {
+		if isBlank(c) || IsMobile(NormalizeMobile(c.Raw)) {
+			return nil
+		}
+		return ErrMobile
+	}
+}
+
+//身份证号码去掉首尾空白，全角转半角，末位x 转为大写
+func NormalizeIDCard(s string) string {
+	return strings.ToUpper(strings.TrimSpace(toHalfWidth(s)))
+}
+
+//从18位居民身份证号码中解析出的信息
+type IDCard struct {
+	Region   string    //前6位行政区划代码
+	Birthday time.Time //出生日期
+	Male     bool      //第17位奇数为男性
+}
+
+var (
+	idCardWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardCodes   = "10X98765432"
+	//省级行政区划代码的前两位
+	idCardProvinces = map[string]bool{
+		"11": true, "12": true, "13": true, "14": true, "15": true, "21": true, "22": true, "23": true,
+		"31": true, "32": true, "33": true, "34": true, "35": true, "36": true, "37": true,
+		"41": true, "42": true, "43": true, "44": true, "45": true, "46": true, "50": true, "51": true,
+		"52": true, "53": true, "54": true, "61": true, "62": true, "63": true, "64": true, "65": true,
+		"71": true, "81": true, "82": true, "83": true, "91": true,
+	}
+)
+
+//校验18位居民身份证号码：省份代码、出生日期及GB 11643 校验码
+func ParseIDCard(s string) (IDCard, error) {
+	var id IDCard
+	s = NormalizeIDCard(s)
+	if len(s) != 18 || !idCardProvinces[s[:2]] {
+		return id, ErrIDCard
+	}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return id, ErrIDCard
+		}
+		sum += int(s[i]-'0') * idCardWeights[i]
+	}
+	if s[17] != idCardCodes[sum%11] {
+		return id, fmt.Errorf("%w: checksum mismatch", ErrIDCard)
+	}
+	birthday, err := time.Parse("20060102", s[6:14])
+	if err != nil || birthday.Year() < 1900 || birthday.After(time.Now()) {
+		return id, fmt.Errorf("%w: invalid birthday", ErrIDCard)
+	}
+	id.Region = s[:6]
+	id.Birthday = birthday
+	id.Male = (s[16]-'0')%2 == 1
+	return id, nil
+}
+
+//身份证号码校验规则
+func IDCardNumber() Rule {
+	return func(c Cell) error {
+		if isBlank(c) {
+			return nil
+		}
+		_, err := ParseIDCard(c.Raw)
+		return err
+	}
+}
+
+//统一社会信用代码使用的字符，I、O、Z、S、V 除外，序号即字符的值
+const usccChars = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+var usccWeights = [17]int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+
+//校验18位统一社会信用代码(GB 32100)的字符及校验码，小写字母视为大写
+func IsUSCC(s string) bool {
+	s = strings.ToUpper(strings.TrimSpace(toHalfWidth(s)))
+	if len(s) != 18 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		v := strings.IndexByte(usccChars, s[i])
+		if v < 0 {
+			return false
+		}
+		sum += v * usccWeights[i]
+	}
+	check := (31 - sum%31) % 31
+	return s[17] == usccChars[check]
+}
+
+//统一社会信用代码校验规则
+func USCC() Rule {
+	return func(c Cell) error {
+		if isBlank(c) || IsUSCC(c.Raw) {
+			return nil
+		}
+		return ErrUSCC
+	}
+}
+
+//身份证号码中的出生日期与出生日期列一致，任意一列为空或身份证号码无效时不校验
+func IDCardBirthday(idColumn, birthdayColumn string) RowRule {
+	return func(rec Record) error {
+		id, err := ParseIDCard(rec.Get(idColumn))
+		if err != nil {
+			return nil
+		}
+		birthday, err := rec.GetTime(birthdayColumn)
+		if err != nil {
+			return nil
+		}
+		if birthday.Format("20060102") != id.Birthday.Format("20060102") {
+			c, _ := rec.Cell(birthdayColumn)
+			return newCellError(c, birthdayColumn, fmt.Errorf("%w: birthday does not match %s %s", ErrInvalid, idColumn, id.Birthday.Format("2006-01-02")))
+		}
+		return nil
+	}
+}
+
+//身份证号码中的性别与性别列(男/女)一致，任意一列为空或身份证号码无效时不校验
+func IDCardGender(idColumn, genderColumn string) RowRule {
+	return func(rec Record) error {
+		id, err := ParseIDCard(rec.Get(idColumn))
+		gender := strings.TrimSpace(rec.Get(genderColumn))
+		if err != nil || (gender != "男" && gender != "女") {
+			return nil
+		}
+		if (gender == "男") != id.Male {
+			c, _ := rec.Cell(genderColumn)
+			return newCellError(c, genderColumn, fmt.Errorf("%w: gender does not match %s", ErrInvalid, idColumn))
+		}
+		return nil
+	}
+}
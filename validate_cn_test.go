@@ -0,0 +1,85 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMobile(t *testing.T) {
+	cases := map[string]bool{
+		"13800138000":       true,
+		"+86 138-0013-8000": true,
+		"8613800138000":     true,
+		"１３８００１３８０００":       true,
+		"12800138000":       false,
+		"1380013800":        false,
+		"1380013800a":       false,
+	}
+	for s, want := range cases {
+		if got := IsMobile(NormalizeMobile(s)); got != want {
+			t.Errorf("%q: got %v", s, got)
+		}
+	}
+}
+
+func TestParseIDCard(t *testing.T) {
+	id, err := ParseIDCard("11010519491231002x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Region != "110105" || id.Male || !id.Birthday.Equal(time.Date(1949, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("id=%+v", id)
+	}
+	for _, s := range []string{"110105194912310021", "11010519491231002", "990105194912310026", "110105194913310028"} {
+		if _, err := ParseIDCard(s); !errors.Is(err, ErrIDCard) {
+			t.Errorf("%s: err=%v", s, err)
+		}
+	}
+}
+
+func TestIsUSCC(t *testing.T) {
+	for s, want := range map[string]bool{
+		"91350100M000100Y43": true,
+		"91350100m000100y43": true,
+		"91350100M000100Y44": false,
+		"91350100M000100I43": false,
+		"91350100M000100Y4":  false,
+	} {
+		if got := IsUSCC(s); got != want {
+			t.Errorf("%s: got %v", s, got)
+		}
+	}
+}
+
+func TestReader_ValidateCN(t *testing.T) {
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1">` +
+		`<c r="A1" t="inlineStr"><is><t>*手机号码</t></is></c><c r="B1" t="inlineStr"><is><t>身份证号</t></is></c>` +
+		`<c r="C1" t="inlineStr"><is><t>性别</t></is></c><c r="D1" t="inlineStr"><is><t>出生日期</t></is></c></row>` +
+		`<row r="2"><c r="A2" t="inlineStr"><is><t>+86 138-0013-8000</t></is></c><c r="B2" t="inlineStr"><is><t>11010519491231002x</t></is></c>` +
+		`<c r="C2" t="inlineStr"><is><t>女</t></is></c><c r="D2" t="inlineStr"><is><t>1949-12-31</t></is></c></row>` +
+		`<row r="3"><c r="A3"><v>12800138000</v></c><c r="B3" t="inlineStr"><is><t>11010519491231002X</t></is></c>` +
+		`<c r="C3" t="inlineStr"><is><t>男</t></is></c><c r="D3" t="inlineStr"><is><t>1950-01-01</t></is></c></row>`}}}
+	spec := HeaderSpec{Normalize: NormAll, Columns: []Column{
+		{Name: "手机号码", Rules: []Rule{Required(), Mobile()}, Transform: NormalizeMobile},
+		{Name: "身份证号", Rules: []Rule{IDCardNumber()}, Transform: NormalizeIDCard},
+		{Name: "性别"},
+		{Name: "出生日期"},
+	}, RowRules: []RowRule{IDCardBirthday("身份证号", "出生日期"), IDCardGender("身份证号", "性别")}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(spec); err != nil {
+		t.Fatal(err)
+	}
+	rows := fetchAll(t, r)
+	if rows[0][0] != "13800138000" || rows[0][1] != "11010519491231002X" {
+		t.Errorf("rows=%q", rows)
+	}
+	var refs []string
+	for _, v := range r.Violations() {
+		refs = append(refs, v.Ref)
+	}
+	if len(refs) != 3 || refs[0] != "A3" || refs[1] != "D3" || refs[2] != "C3" {
+		t.Errorf("violations=%v", r.Violations())
+	}
+}
@@ -0,0 +1,112 @@
+package xlsx_reader
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var validateFixture = fixture{
+	sheets: []fixtureSheet{{name: "会员", sheetData: `<row r="1">` +
+		`<c r="A1" t="inlineStr"><is><t>手机号码</t></is></c><c r="B1" t="inlineStr"><is><t>会员昵称</t></is></c>` +
+		`<c r="C1" t="inlineStr"><is><t>性别</t></is></c><c r="D1" t="inlineStr"><is><t>积分</t></is></c>` +
+		`<c r="E1" t="inlineStr"><is><t>出生日期</t></is></c><c r="F1" t="inlineStr"><is><t>已用积分</t></is></c></row>` +
+		`<row r="2"><c r="A2"><v>13800138000</v></c><c r="B2" t="inlineStr"><is><t>张三</t></is></c>` +
+		`<c r="C2" t="inlineStr"><is><t>男</t></is></c><c r="D2"><v>100</v></c><c r="E2" t="inlineStr"><is><t>2000-01-01</t></is></c><c r="F2"><v>10</v></c></row>` +
+		`<row r="3"><c r="A3"><v>12345</v></c><c r="C3" t="inlineStr"><is><t>未知</t></is></c>` +
+		`<c r="D3"><v>-1</v></c><c r="E3" t="inlineStr"><is><t>1800-01-01</t></is></c></row>` +
+		`<row r="4"><c r="A4"><v>13800138000</v></c><c r="B4" t="inlineStr"><is><t>这个昵称实在是太长了</t></is></c><c r="D4"><v>5</v></c><c r="F4"><v>8</v></c></row>`}},
+}
+
+func validateSpec() HeaderSpec {
+	return HeaderSpec{Columns: []Column{
+		{Name: "手机号码", Rules: []Rule{Required(), Pattern(`^1\d{10}$`)}, Unique: true},
+		{Name: "会员昵称", Rules: []Rule{Required(), Length(1, 8)}},
+		{Name: "性别", Rules: []Rule{OneOf("男", "女")}},
+		{Name: "积分", Rules: []Rule{Between(0, 10000)}},
+		{Name: "出生日期", Rules: []Rule{DateBetween(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{})}},
+		{Name: "已用积分"},
+	}, RowRules: []RowRule{func(rec Record) error {
+		total, _ := rec.GetInt("积分")
+		used, err := rec.GetInt("已用积分")
+		if err == nil && used > total {
+			c, _ := rec.Cell("已用积分")
+			return newCellError(c, "已用积分", errors.New("exceeds 积分"))
+		}
+		return nil
+	}}}
+}
+
+func TestReader_Validate(t *testing.T) {
+	r := ReaderFromBytes(validateFixture.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(validateSpec()); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[int]int{}
+	if err := r.FetchRowWithNumber(func(n int, row []string) error {
+		counts[n] = len(r.RowViolations())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if counts[2] != 0 || counts[3] != 5 || counts[4] != 3 {
+		t.Errorf("counts=%v", counts)
+	}
+	var got []string
+	for _, v := range r.Violations() {
+		got = append(got, fmt.Sprintf("%s!%s %s", v.Sheet, v.Ref, v.Column))
+	}
+	want := []string{"会员!A3 手机号码", "会员!B3 会员昵称", "会员!C3 性别", "会员!D3 积分", "会员!E3 出生日期",
+		"会员!A4 手机号码", "会员!B4 会员昵称", "会员!F4 已用积分"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("violations=%q", got)
+	}
+	if !errors.Is(r.Violations()[0].Err, ErrInvalid) || !errors.Is(r.Violations()[5], ErrDuplicate) {
+		t.Errorf("errors=%v", r.Violations())
+	}
+
+	r = ReaderFromBytes(validateFixture.bytes(t), "", true)
+	r.SetMaxErrors(3)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(validateSpec()); err != nil {
+		t.Fatal(err)
+	}
+	rows, last := 0, 0
+	err := r.FetchRow(func(row []string) error {
+		rows++
+		last = len(r.RowViolations())
+		return nil
+	})
+	//达到错误数的行仍然输出
+	if !errors.Is(err, ErrTooManyErrors) || rows != 2 || last != 5 {
+		t.Errorf("rows=%d violations=%d err=%v", rows, last, err)
+	}
+}
+
+func TestReader_ValidateDate1904(t *testing.T) {
+	f := fixture{sheets: []fixtureSheet{{name: "Sheet1", sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>日期</t></is></c></row>` +
+		`<row r="2"><c r="A2"><v>0</v></c></row>`}}, date1904: true}
+	spec := HeaderSpec{Columns: []Column{{Name: "日期", Rules: []Rule{DateBetween(time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1904, 1, 31, 0, 0, 0, 0, time.UTC))}}}}
+	r := ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(spec); err != nil {
+		t.Fatal(err)
+	}
+	for r.Next() {
+	}
+	if r.Err() != nil || len(r.Violations()) != 0 {
+		t.Errorf("err=%v violations=%v", r.Err(), r.Violations())
+	}
+
+	//没有规则时不解析单元格
+	r = ReaderFromBytes(f.bytes(t), "", true)
+	defer r.Close()
+	if err := r.OpenAndValidSpec(NewHeaderSpec("日期")); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Next() || r.cells != nil {
+		t.Errorf("cells=%v", r.cells)
+	}
+}
@@ -0,0 +1,270 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//工作表信息
+type SheetInfo struct {
+	Name    string //工作表名称
+	SheetID int    //workbook.xml 中的sheetId
+	Index   int    //工作表顺序，从0开始
+	State   string //可见状态：visible、hidden、veryHidden
+
+	path string //工作表在压缩包中的路径
+}
+
+//是否可见
+func (this SheetInfo) Visible() bool {
+	return this.State == "" || this.State == "visible"
+}
+
+//已打开的xlsx 文件，workbook.xml 只解析一次，
+//多个工作表读取器共享同一个压缩包句柄及共享字符串缓存
+type Workbook struct {
+	policy Policy //新建工作表读取器时使用的读取策略
+
+	reader      *zip.Reader
+	closer      io.Closer //数据源需要关闭时不为nil
+	sheets      []SheetInfo
+	shareString *zip.File
+	styleSheet  *zip.File
+	calcChain   *zip.File //公式的计算链，修改公式单元格后需删除
+	date1904    bool      //workbookPr 中的date1904，为true 时日期从1904-01-01 开始计算
+
+	templateName    string //Template.Generate 生成的模板中隐藏的模板名称及版本
+	templateVersion string
+
+	//Fast策略的共享字符串缓存，首次使用时解析
+	stringOnce  sync.Once
+	stringCache []string
+	stringErr   error
+
+	//Indexed策略的共享字符串索引，首次使用时创建，Close 时删除临时文件
+	indexOnce       sync.Once
+	stringIndex     *stringIndex
+	stringIndexErr  error
+	stringCacheSize int //最近使用的字符串的缓存数量
+
+	//styles.xml 首次使用时解析
+	stylesOnce sync.Once
+	styles     *styles
+	stylesErr  error
+}
+
+//fileName:xlsx 文件路径及名称
+func OpenWorkbook(fileName string) (*Workbook, error) {
+	return newWorkbook(openFileZip(fileName))
+}
+
+//r:xlsx 文件内容,size:文件大小
+func OpenWorkbookFromReaderAt(r io.ReaderAt, size int64) (*Workbook, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrFileType
+	}
+	return newWorkbook(zr, nil, nil)
+}
+
+//data:内存中的xlsx 文件内容
+func OpenWorkbookFromBytes(data []byte) (*Workbook, error) {
+	return OpenWorkbookFromReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+//fsys:文件系统,name:xlsx 文件在fsys中的路径
+func OpenWorkbookFromFS(fsys fs.FS, name string) (*Workbook, error) {
+	return newWorkbook(openFSZip(fsys, name))
+}
+
+//解析workbook.xml 及其关系文件，得到全部工作表
+func newWorkbook(zr *zip.Reader, closer io.Closer, err error) (*Workbook, error) {
+	if err != nil {
+		return nil, err
+	}
+	this := &Workbook{policy: Fast, reader: zr, closer: closer}
+	var workbook xlsxWorkbook
+	var bookrel xlsxWorkbookRels
+	//workbook 文件较小所以可以全量解析
+	for _, file := range zr.File {
+		switch file.Name {
+		case "xl/workbook.xml":
+			err = decodeZip(file, &workbook)
+		case "xl/_rels/workbook.xml.rels":
+			err = decodeZip(file, &bookrel)
+		case "xl/sharedStrings.xml":
+			this.shareString = file
+		case "xl/styles.xml":
+			this.styleSheet = file
+		case "xl/calcChain.xml":
+			this.calcChain = file
+		}
+		if err != nil {
+			this.Close()
+			return nil, err
+		}
+	}
+	if len(workbook.Sheets.Sheet) == 0 {
+		this.Close()
+		return nil, ErrFileType
+	}
+	this.date1904 = workbook.WorkbookPr.Date1904
+	for _, n := range workbook.DefinedNames.DefinedName {
+		switch n.Name {
+		case templateNameKey:
+			this.templateName = unquoteFormula(n.Data)
+		case templateVersionKey:
+			this.templateVersion = unquoteFormula(n.Data)
+		}
+	}
+	targets := make(map[string]string, len(bookrel.Relationships))
+	for _, rel := range bookrel.Relationships {
+		targets[rel.ID] = rel.Target
+	}
+	this.sheets = make([]SheetInfo, 0, len(workbook.Sheets.Sheet))
+	for i, sheet := range workbook.Sheets.Sheet {
+		id, _ := strconv.Atoi(sheet.SheetID)
+		this.sheets = append(this.sheets, SheetInfo{
+			Name:    sheet.Name,
+			SheetID: id,
+			Index:   i,
+			State:   sheet.State,
+			path:    sheetPath(targets[sheet.ID]),
+		})
+	}
+	return this, nil
+}
+
+//关系文件中的Target 可能是相对xl/目录的路径，也可能是以/开头的绝对路径
+func sheetPath(target string) string {
+	if target == "" {
+		return ""
+	}
+	if strings.HasPrefix(target, "/") {
+		return target[1:]
+	}
+	return "xl/" + target
+}
+
+//日期是否从1904-01-01 开始计算，可作为GetExcelTime 的date1904 参数
+func (this *Workbook) Date1904() bool {
+	return this.date1904
+}
+
+//由Template.Generate 生成的模板中记录的模板名称及版本，其他文件返回空字符串
+func (this *Workbook) TemplateVersion() (name, version string) {
+	return this.templateName, this.templateVersion
+}
+
+//设置之后新建的工作表读取器所使用的读取策略
+func (this *Workbook) SetPolicy(policy Policy) {
+	this.policy = policy
+}
+
+//Indexed 策略中缓存的最近使用的字符串数量，需在读取之前调用，小于等于0 时为DefaultStringCacheSize
+func (this *Workbook) SetStringCacheSize(size int) {
+	this.stringCacheSize = size
+}
+
+//按workbook.xml 中的顺序返回全部工作表
+func (this *Workbook) Sheets() []SheetInfo {
+	sheets := make([]SheetInfo, len(this.sheets))
+	copy(sheets, this.sheets)
+	return sheets
+}
+
+//sheetName:工作表名称，如果为空则读取第一个,firstRowIsCol:首行为列名
+//返回的读取器仍需调用Open，关闭读取器不会关闭Workbook
+func (this *Workbook) SheetReader(sheetName string, firstRowIsCol bool) (*reader, error) {
+	sheet, err := this.findSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	return this.newSheetReader(sheet, firstRowIsCol), nil
+}
+
+//index:工作表顺序，从0开始
+func (this *Workbook) SheetReaderAt(index int, firstRowIsCol bool) (*reader, error) {
+	if index < 0 || index >= len(this.sheets) {
+		return nil, ErrSheetName
+	}
+	return this.newSheetReader(this.sheets[index], firstRowIsCol), nil
+}
+
+func (this *Workbook) newSheetReader(sheet SheetInfo, firstRowIsCol bool) *reader {
+	r := newReader("", sheet.Name, firstRowIsCol, this.policy)
+	r.workbook = this
+	r.openZip = nil
+	return r
+}
+
+func (this *Workbook) findSheet(sheetName string) (SheetInfo, error) {
+	if sheetName == "" {
+		return this.sheets[0], nil
+	}
+	for _, sheet := range this.sheets {
+		if sheet.Name == sheetName {
+			return sheet, nil
+		}
+	}
+	return SheetInfo{}, ErrSheetName
+}
+
+func (this *Workbook) file(name string) *zip.File {
+	for _, file := range this.reader.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
+
+//解析后的共享字符串，多个工作表读取器只解析一次
+func (this *Workbook) sharedStrings() ([]string, error) {
+	this.stringOnce.Do(func() {
+		this.stringCache, this.stringErr = decodeSharedStrings(this.shareString)
+	})
+	return this.stringCache, this.stringErr
+}
+
+//Indexed策略的共享字符串索引，多个工作表读取器只创建一次，没有sharedStrings.xml 时为nil
+func (this *Workbook) indexedStrings() (*stringIndex, error) {
+	this.indexOnce.Do(func() {
+		if this.shareString != nil {
+			this.stringIndex, this.stringIndexErr = newStringIndex(this.shareString, "", this.stringCacheSize)
+		}
+	})
+	return this.stringIndex, this.stringIndexErr
+}
+
+//解析后的样式表，多个工作表读取器只解析一次，没有styles.xml 时为nil
+func (this *Workbook) cellStyles() (*styles, error) {
+	this.stylesOnce.Do(func() {
+		if this.styleSheet == nil {
+			return
+		}
+		var sheet xlsxStyleSheet
+		if this.stylesErr = decodeZip(this.styleSheet, &sheet); this.stylesErr == nil {
+			this.styles = newStyles(&sheet)
+		}
+	})
+	return this.styles, this.stylesErr
+}
+
+//关闭工作簿并删除Indexed 策略的临时文件，之后工作表读取器读到的共享字符串为空，
+//应在全部读取结束后调用
+func (this *Workbook) Close() error {
+	this.indexOnce.Do(func() {}) //等待正在创建的索引，之后不再创建
+	if this.stringIndex != nil {
+		this.stringIndex.Close()
+	}
+	if this.closer != nil {
+		return this.closer.Close()
+	}
+	return nil
+}
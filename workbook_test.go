@@ -0,0 +1,82 @@
+package xlsx_reader
+
+import (
+	"reflect"
+	"testing"
+)
+
+var multiSheetFixture = fixture{
+	sheets: []fixtureSheet{
+		{name: "会员", sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c></row><row r="2"><c r="A2" t="s"><v>1</v></c></row>`},
+		{name: "说明", state: "hidden", sheetData: `<row r="1"><c r="A1" t="s"><v>2</v></c></row>`},
+		{name: "订单", sheetData: `<row r="1"><c r="A1"><v>42</v></c></row>`},
+	},
+	strings: []string{"姓名", "张三", "请勿修改"},
+}
+
+func TestWorkbook_Sheets(t *testing.T) {
+	wb, err := OpenWorkbookFromBytes(multiSheetFixture.bytes(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	sheets := wb.Sheets()
+	if len(sheets) != 3 {
+		t.Fatalf("sheets=%v", sheets)
+	}
+	for i, name := range []string{"会员", "说明", "订单"} {
+		if sheets[i].Name != name || sheets[i].Index != i || sheets[i].SheetID != i+1 {
+			t.Errorf("sheet %d=%+v", i, sheets[i])
+		}
+	}
+	if !sheets[0].Visible() || sheets[1].Visible() || sheets[1].State != "hidden" {
+		t.Errorf("state: %+v", sheets)
+	}
+}
+
+func TestWorkbook_SheetReaders(t *testing.T) {
+	wb, err := OpenWorkbookFromBytes(multiSheetFixture.bytes(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	r1, err := wb.SheetReader("会员", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := wb.SheetReaderAt(2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols, err := r1.Open()
+	if err != nil || !reflect.DeepEqual(cols, []string{"姓名"}) {
+		t.Fatalf("cols=%v err=%v", cols, err)
+	}
+	if _, err = r2.Open(); err != nil {
+		t.Fatal(err)
+	}
+	//交替读取两个工作表
+	if rows := fetchAll(t, r2); !reflect.DeepEqual(rows, [][]string{{"42"}}) {
+		t.Errorf("订单 rows=%v", rows)
+	}
+	r2.Close()
+	if rows := fetchAll(t, r1); !reflect.DeepEqual(rows, [][]string{{"张三"}}) {
+		t.Errorf("会员 rows=%v", rows)
+	}
+	r1.Close()
+
+	if _, err = wb.SheetReader("不存在", true); err != ErrSheetName {
+		t.Errorf("err=%v", err)
+	}
+	if _, err = wb.SheetReaderAt(3, true); err != ErrSheetName {
+		t.Errorf("err=%v", err)
+	}
+}
+
+func TestReader_UnknownSheet(t *testing.T) {
+	r := ReaderFromBytes(multiSheetFixture.bytes(t), "不存在", true)
+	defer r.Close()
+	if _, err := r.Open(); err != ErrSheetName {
+		t.Errorf("err=%v", err)
+	}
+}
@@ -0,0 +1,687 @@
+package xlsx_reader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+var (
+	ErrNoSheet          = errors.New("No sheet to write, call AddSheet first")
+	ErrSheetStarted     = errors.New("Sheet options must be set before the first row")
+	ErrWriterClosed     = errors.New("Writer is closed")
+	ErrInvalidSheetName = errors.New("Sheet name is empty, too long, duplicated or contains []:*?/\\")
+	ErrRowOrder         = errors.New("Rows must be written in ascending order")
+	ErrTooManyColumns   = errors.New("Row has more than 16384 columns")
+)
+
+//写入时使用的样式，cellXfs 中的序号
+const (
+	styleDefault  = 0
+	styleDate     = 1 //yyyy-mm-dd
+	styleDateTime = 2 //yyyy-mm-dd hh:mm:ss
+	styleText     = 3 //@ 文本格式
+	styleHeader   = 4 //粗体，列名
+	styleRequired = 5 //红色粗体，必填列的列名
+
+	maxListFormula = 255 //下拉列表直接写在公式中时的最大长度
+)
+
+//带样式的单元格值
+type styledValue struct {
+	value interface{}
+	style int
+}
+
+//已写入的工作表
+type writerSheet struct {
+	name  string
+	state string //hidden 为隐藏，为空时可见
+}
+
+//数据有效性
+type writerValidation struct {
+	ref     string //单元格区域，如C2:C1048576
+	formula string //下拉列表的值，如"男,女"或'_lists'!$A$1:$A$9
+}
+
+//逐行写入xlsx 文件，内存占用为一行数据加共享字符串表
+//工作表按AddSheet 的顺序依次写入，添加下一个工作表后不能再写入之前的工作表
+type writer struct {
+	zw            *zip.Writer
+	sharedStrings bool           //字符串写入共享字符串表，false 时使用内联字符串
+	stringIndex   map[string]int //共享字符串的序号
+	stringList    []string
+	sheets        []writerSheet
+	names         []xlsxDefinedName //workbook.xml 中的定义名称
+	closed        bool
+
+	//当前工作表
+	out         *xmlWriter
+	widths      []float64 //列宽，0 为默认宽度
+	colStyles   []int     //列的默认样式
+	freeze      int       //冻结的行数
+	autoFilter  bool
+	started     bool //已写入<sheetData>
+	validations []writerValidation
+	rowNum      int //已写入的最后一行
+	maxCol      int //已写入的最大列序号
+}
+
+//w:写入xlsx 文件的目标，Close 之后内容才完整
+func Writer(w io.Writer) *writer {
+	return &writer{
+		zw:            zip.NewWriter(w),
+		sharedStrings: true,
+		stringIndex:   make(map[string]int),
+		maxCol:        -1,
+	}
+}
+
+//字符串是否写入共享字符串表，默认为true，需在写入之前调用
+//重复的字符串较多时共享字符串表文件较小，false 时使用内联字符串，不需要在内存中保存字符串表
+func (this *writer) SetSharedStrings(shared bool) {
+	this.sharedStrings = shared
+}
+
+//工作表名称不能为空、超过31个字符、重复或包含[]:*?/\
+func (this *writer) checkSheetName(name string) error {
+	if name == "" || len([]rune(name)) > 31 || strings.ContainsAny(name, `[]:*?/\`) {
+		return ErrInvalidSheetName
+	}
+	for _, s := range this.sheets {
+		if strings.EqualFold(s.name, name) {
+			return ErrInvalidSheetName
+		}
+	}
+	return nil
+}
+
+//添加工作表，之后写入的行都在该工作表中，之前的工作表写入完毕
+func (this *writer) AddSheet(name string) error {
+	if this.closed {
+		return ErrWriterClosed
+	}
+	if err := this.checkSheetName(name); err != nil {
+		return err
+	}
+	if err := this.finishSheet(); err != nil {
+		return err
+	}
+	fw, err := this.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(this.sheets)+1))
+	if err != nil {
+		return err
+	}
+	this.sheets = append(this.sheets, writerSheet{name: name})
+	this.out = newXmlWriter(fw)
+	this.widths, this.colStyles, this.freeze, this.autoFilter, this.started = nil, nil, 0, false, false
+	this.validations = nil
+	this.rowNum, this.maxCol = 0, -1
+	return nil
+}
+
+//当前工作表的列宽，按字符数计算，0 为默认宽度，需在写入第一行之前调用
+func (this *writer) SetColWidths(widths ...float64) error {
+	if err := this.checkOptions(); err != nil {
+		return err
+	}
+	this.widths = widths
+	return nil
+}
+
+//冻结当前工作表的前rows 行，一般为1，即冻结列名行，需在写入第一行之前调用
+func (this *writer) SetFreezeRows(rows int) error {
+	if err := this.checkOptions(); err != nil {
+		return err
+	}
+	this.freeze = rows
+	return nil
+}
+
+//当前工作表的第一行添加自动筛选，区域为写入的全部行及列，需在写入第一行之前调用
+func (this *writer) SetAutoFilter(filter bool) error {
+	if err := this.checkOptions(); err != nil {
+		return err
+	}
+	this.autoFilter = filter
+	return nil
+}
+
+//当前工作表中ref 区域(如"C2:C1000")的单元格只能从values 中选择，可以在写入过程中调用
+//values 以逗号连接后不能超过255 个字符，且不能包含逗号及双引号
+func (this *writer) AddDropdown(ref string, values ...string) error {
+	if this.out == nil {
+		return ErrNoSheet
+	}
+	if _, err := cellref.ParseRange(ref); err != nil {
+		return err
+	}
+	formula, ok := listFormula(values)
+	if !ok {
+		return fmt.Errorf("%w: dropdown values too long or contain ',' '\"'", ErrInvalid)
+	}
+	return this.addValidation(ref, formula)
+}
+
+//当前工作表中ref 区域的下拉列表，formula 为"男,女"或其他工作表中的区域
+func (this *writer) addValidation(ref, formula string) error {
+	if this.out == nil {
+		return ErrNoSheet
+	}
+	this.validations = append(this.validations, writerValidation{ref: ref, formula: formula})
+	return nil
+}
+
+//当前工作表各列的默认样式，需在写入第一行之前调用
+func (this *writer) setColStyles(styles ...int) error {
+	if err := this.checkOptions(); err != nil {
+		return err
+	}
+	this.colStyles = styles
+	return nil
+}
+
+//当前工作表的可见性：visible、hidden 或veryHidden，veryHidden 的工作表在Excel 中不能取消隐藏
+func (this *writer) SetSheetState(state string) error {
+	if this.out == nil {
+		return ErrNoSheet
+	}
+	switch state {
+	case "visible":
+		state = ""
+	case "hidden", "veryHidden":
+	default:
+		return fmt.Errorf("%w: sheet state %q", ErrInvalid, state)
+	}
+	this.sheets[len(this.sheets)-1].state = state
+	return nil
+}
+
+//添加工作簿范围的定义名称，formula 为公式或常量，如Sheet1!$A$1:$A$9、"v2"，hidden 为true 时在名称管理器中不可见
+func (this *writer) DefineName(name, formula string, hidden bool) error {
+	if this.closed {
+		return ErrWriterClosed
+	}
+	if name == "" || formula == "" {
+		return fmt.Errorf("%w: empty defined name", ErrInvalid)
+	}
+	for _, n := range this.names {
+		if n.LocalSheetID == nil && strings.EqualFold(n.Name, name) {
+			return fmt.Errorf("%w: duplicated defined name %s", ErrInvalid, name)
+		}
+	}
+	this.names = append(this.names, xlsxDefinedName{Name: name, Hidden: hidden, Data: formula})
+	return nil
+}
+
+//下拉列表直接写在公式中，如"男,女"
+func listFormula(values []string) (string, bool) {
+	s := strings.Join(values, ",")
+	if len(values) == 0 || len(s) > maxListFormula || strings.Contains(s, `"`) || len(strings.Split(s, ",")) != len(values) {
+		return "", false
+	}
+	return `"` + s + `"`, true
+}
+
+func (this *writer) checkOptions() error {
+	if this.out == nil {
+		return ErrNoSheet
+	}
+	if this.started {
+		return ErrSheetStarted
+	}
+	return nil
+}
+
+//写入<sheetData>之前的内容
+func (this *writer) startSheet() {
+	this.started = true
+	out := this.out
+	out.raw(xml.Header)
+	out.start("worksheet", attr("xmlns", "http://schemas.openxmlformats.org/spreadsheetml/2006/main"),
+		xml.Attr{Name: xml.Name{Space: "xmlns", Local: "r"}, Value: relsNamespace})
+	if this.freeze > 0 {
+		cell := cellref.Ref{Row: this.freeze + 1}.String()
+		out.start("sheetViews")
+		out.start("sheetView", attr("workbookViewId", "0"))
+		out.element("pane", attr("ySplit", strconv.Itoa(this.freeze)), attr("topLeftCell", cell), attr("activePane", "bottomLeft"), attr("state", "frozen"))
+		out.element("selection", attr("pane", "bottomLeft"), attr("activeCell", cell), attr("sqref", cell))
+		out.end("sheetView")
+		out.end("sheetViews")
+	}
+	if len(this.widths) > 0 || len(this.colStyles) > 0 {
+		out.start("cols")
+		for i := 0; i < len(this.widths) || i < len(this.colStyles); i++ {
+			var w float64
+			var style int
+			if i < len(this.widths) {
+				w = this.widths[i]
+			}
+			if i < len(this.colStyles) {
+				style = this.colStyles[i]
+			}
+			if w <= 0 && style == styleDefault {
+				continue
+			}
+			n := strconv.Itoa(i + 1)
+			attrs := []xml.Attr{attr("min", n), attr("max", n)}
+			if w > 0 {
+				attrs = append(attrs, attr("width", strconv.FormatFloat(w, 'f', -1, 64)), attr("customWidth", "1"))
+			} else {
+				attrs = append(attrs, attr("width", "9.140625"))
+			}
+			if style != styleDefault {
+				attrs = append(attrs, attr("style", strconv.Itoa(style)))
+			}
+			out.element("col", attrs...)
+		}
+		out.end("cols")
+	}
+	out.start("sheetData")
+}
+
+//写入当前工作表的下一行，见WriteRowAt
+func (this *writer) WriteRow(values ...interface{}) error {
+	return this.WriteRowAt(this.rowNum+1, values...)
+}
+
+//写入字符串行
+func (this *writer) WriteStrings(values []string) error {
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		row[i] = v
+	}
+	return this.WriteRow(row...)
+}
+
+//在rowNum 行(从1开始)写入values，rowNum 必须大于已写入的行(否则为ErrRowOrder)且不超过1048576，
+//values 不超过16384 列，中间的行为空行
+//支持string、整数、浮点数、bool、time.Time(日期格式)、Cell、fmt.Stringer，nil 及空字符串为空单元格，
+//其他类型按fmt.Sprint 写入字符串
+func (this *writer) WriteRowAt(rowNum int, values ...interface{}) error {
+	if this.closed {
+		return ErrWriterClosed
+	}
+	if this.out == nil {
+		return ErrNoSheet
+	}
+	if rowNum <= this.rowNum {
+		return fmt.Errorf("%w: row %d after row %d", ErrRowOrder, rowNum, this.rowNum)
+	}
+	if rowNum > cellref.MaxRows {
+		return fmt.Errorf("%w: row %d exceeds %d", cellref.ErrRef, rowNum, cellref.MaxRows)
+	}
+	if len(values) > cellref.MaxColumns {
+		return ErrTooManyColumns
+	}
+	if !this.started {
+		this.startSheet()
+	}
+	this.rowNum = rowNum
+	out := this.out
+	out.start("row", attr("r", strconv.Itoa(rowNum)))
+	for col, v := range values {
+		this.writeCell(rowNum, col, v)
+	}
+	out.end("row")
+	return nil
+}
+
+//写入一个单元格，空值不写入
+func (this *writer) writeCell(rowNum, col int, v interface{}) {
+	style := styleDefault
+	if sv, ok := v.(styledValue); ok {
+		v, style = sv.value, sv.style
+	}
+	t, value, vstyle := encodeValue(v, false)
+	if value == "" {
+		return
+	}
+	if style == styleDefault {
+		style = vstyle
+	}
+	if t == "inlineStr" {
+		t, value = this.stringValue(value)
+	}
+	if col > this.maxCol {
+		this.maxCol = col
+	}
+	attrs := []xml.Attr{attr("r", cellref.Ref{Col: col, Row: rowNum}.String())}
+	if style != styleDefault {
+		attrs = append(attrs, attr("s", strconv.Itoa(style)))
+	}
+	if t != "" {
+		attrs = append(attrs, attr("t", t))
+	}
+	this.out.start("c", attrs...)
+	writeCellValue(this.out, "", t, value)
+	this.out.end("c")
+}
+
+//单元格的值转换为<c>的t 属性及<v>中的文本，字符串的t 为inlineStr，text 为字符串内容
+//text 为空时为空单元格，time.Time 的style 为styleDate 或styleDateTime，date1904 为日期系统
+func encodeValue(v interface{}, date1904 bool) (t, text string, style int) {
+	switch x := v.(type) {
+	case nil:
+	case string:
+		t, text = "inlineStr", x
+	case bool:
+		t, text = "b", "0"
+		if x {
+			text = "1"
+		}
+	case int:
+		text = strconv.Itoa(x)
+	case int8, int16, int32, int64:
+		text = fmt.Sprint(x)
+	case uint, uint8, uint16, uint32, uint64:
+		text = fmt.Sprint(x)
+	case float32:
+		text = formatNumber(float64(x))
+	case float64:
+		text = formatNumber(x)
+	case time.Time:
+		if x.IsZero() {
+			break
+		}
+		text, style = formatNumber(ExcelSerial(x, date1904)), styleDate
+		if x.Hour() != 0 || x.Minute() != 0 || x.Second() != 0 {
+			style = styleDateTime
+		}
+	case Cell:
+		return encodeValue(cellValue(x), date1904)
+	case fmt.Stringer:
+		t, text = "inlineStr", x.String()
+	default:
+		t, text = "inlineStr", fmt.Sprint(x)
+	}
+	return
+}
+
+//写入<c>中的值，prefix 为元素的命名空间前缀
+func writeCellValue(out *xmlWriter, prefix, t, text string) {
+	name := func(local string) string {
+		if prefix == "" {
+			return local
+		}
+		return prefix + ":" + local
+	}
+	text = escapeCellText(text)
+	if t == "inlineStr" {
+		out.start(name("is"))
+		out.textElement(name("t"), text, spaceAttr(text)...)
+		out.end(name("is"))
+	} else {
+		out.textElement(name("v"), text)
+	}
+}
+
+//首尾有空白时需要xml:space="preserve"
+func spaceAttr(s string) []xml.Attr {
+	if strings.TrimSpace(s) != s {
+		return []xml.Attr{attr("xml:space", "preserve")}
+	}
+	return nil
+}
+
+//字符串单元格的类型及<v>中的值
+func (this *writer) stringValue(s string) (string, string) {
+	if !this.sharedStrings {
+		return "inlineStr", s
+	}
+	i, ok := this.stringIndex[s]
+	if !ok {
+		i = len(this.stringList)
+		this.stringIndex[s] = i
+		this.stringList = append(this.stringList, s)
+	}
+	return "s", strconv.Itoa(i)
+}
+
+//NaN 及无穷大不能写入xlsx，写入为空单元格
+func formatNumber(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+//读取到的单元格按类型写入
+func cellValue(c Cell) interface{} {
+	if c.Value == nil && c.Kind != KindEmpty {
+		c.parse(false)
+	}
+	switch c.Kind {
+	case KindEmpty:
+		return nil
+	case KindNumber, KindBool, KindDate:
+		return c.Value
+	}
+	return c.Raw
+}
+
+//结束当前工作表
+func (this *writer) finishSheet() error {
+	if this.out == nil {
+		return nil
+	}
+	if !this.started {
+		this.startSheet()
+	}
+	out := this.out
+	out.end("sheetData")
+	if this.autoFilter && this.maxCol >= 0 {
+		rng := cellref.Range{Start: cellref.Ref{Col: 0, Row: 1}, End: cellref.Ref{Col: this.maxCol, Row: this.rowNum}}
+		out.element("autoFilter", attr("ref", rng.String()))
+		index := len(this.sheets) - 1
+		rng.Start.ColAbs, rng.Start.RowAbs, rng.End.ColAbs, rng.End.RowAbs = true, true, true, true
+		this.names = append(this.names, xlsxDefinedName{Name: "_xlnm._FilterDatabase", LocalSheetID: &index, Hidden: true,
+			Data: quoteSheetName(this.sheets[index].name) + "!" + rng.String()})
+	}
+	if len(this.validations) > 0 {
+		out.start("dataValidations", attr("count", strconv.Itoa(len(this.validations))))
+		for _, v := range this.validations {
+			out.start("dataValidation", attr("type", "list"), attr("allowBlank", "1"), attr("showErrorMessage", "1"), attr("sqref", v.ref))
+			out.textElement("formula1", v.formula)
+			out.end("dataValidation")
+		}
+		out.end("dataValidations")
+	}
+	out.end("worksheet")
+	this.out = nil
+	return out.Flush()
+}
+
+//结束最后一个工作表，写入共享字符串表、样式表及工作簿等文件，不会关闭w
+func (this *writer) Close() error {
+	if this.closed {
+		return nil
+	}
+	if len(this.sheets) == 0 {
+		//至少需要一个工作表
+		if err := this.AddSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err := this.finishSheet(); err != nil {
+		return err
+	}
+	this.closed = true
+	for _, part := range []func() error{this.writeSharedStrings, this.writeStyles, this.writeWorkbook, this.writeContentTypes} {
+		if err := part(); err != nil {
+			return err
+		}
+	}
+	return this.zw.Close()
+}
+
+//写入xml 文件
+func (this *writer) writePart(name string, fn func(out *xmlWriter)) error {
+	fw, err := this.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	out := newXmlWriter(fw)
+	out.raw(xml.Header)
+	fn(out)
+	return out.Flush()
+}
+
+func (this *writer) writeSharedStrings() error {
+	if len(this.stringList) == 0 {
+		return nil
+	}
+	return this.writePart("xl/sharedStrings.xml", func(out *xmlWriter) {
+		n := strconv.Itoa(len(this.stringList))
+		out.start("sst", attr("xmlns", "http://schemas.openxmlformats.org/spreadsheetml/2006/main"), attr("count", n), attr("uniqueCount", n))
+		for _, s := range this.stringList {
+			out.start("si")
+			out.textElement("t", escapeCellText(s), spaceAttr(s)...)
+			out.end("si")
+		}
+		out.end("sst")
+	})
+}
+
+//样式表：默认样式、日期、日期时间、文本格式、列名及必填列名
+func (this *writer) writeStyles() error {
+	numFmts := xlsxNumFmts{Count: 2, NumFmt: []xlsxNumFmt{{NumFmtID: 176, FormatCode: "yyyy-mm-dd"}, {NumFmtID: 177, FormatCode: "yyyy-mm-dd hh:mm:ss"}}}
+	xfs := xlsxCellXfs{Count: 6, Xf: []xlsxXf{{}, {NumFmtID: 176, ApplyNumberFormat: true}, {NumFmtID: 177, ApplyNumberFormat: true},
+		{NumFmtID: 49, ApplyNumberFormat: true}, {FontID: 1, ApplyFont: true}, {FontID: 2, ApplyFont: true}}}
+	fw, err := this.zw.Create("xl/styles.xml")
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(fw, xml.Header+`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(fw)
+	if err = enc.EncodeElement(numFmts, xml.StartElement{Name: xml.Name{Local: "numFmts"}}); err != nil {
+		return err
+	}
+	if err = enc.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, `<fonts count="3"><font><sz val="11"/><name val="Calibri"/><family val="2"/></font>`+
+		`<font><b/><sz val="11"/><name val="Calibri"/><family val="2"/></font>`+
+		`<font><b/><sz val="11"/><color rgb="FFFF0000"/><name val="Calibri"/><family val="2"/></font></fonts>`+
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>`+
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`+
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	if err != nil {
+		return err
+	}
+	if err = enc.EncodeElement(xfs, xml.StartElement{Name: xml.Name{Local: "cellXfs"}}); err != nil {
+		return err
+	}
+	if err = enc.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, `<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+	return err
+}
+
+//workbook.xml 及关系文件
+func (this *writer) writeWorkbook() error {
+	err := this.writePart("xl/workbook.xml", func(out *xmlWriter) {
+		out.start("workbook", attr("xmlns", "http://schemas.openxmlformats.org/spreadsheetml/2006/main"),
+			xml.Attr{Name: xml.Name{Space: "xmlns", Local: "r"}, Value: relsNamespace})
+		out.start("sheets")
+		for i, s := range this.sheets {
+			attrs := []xml.Attr{attr("name", s.name), attr("sheetId", strconv.Itoa(i+1))}
+			if s.state != "" {
+				attrs = append(attrs, attr("state", s.state))
+			}
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "r", Local: "id"}, Value: "rId" + strconv.Itoa(i+1)})
+			out.element("sheet", attrs...)
+		}
+		out.end("sheets")
+		if len(this.names) > 0 {
+			out.start("definedNames")
+			for _, n := range this.names {
+				attrs := []xml.Attr{attr("name", n.Name)}
+				if n.LocalSheetID != nil {
+					attrs = append(attrs, attr("localSheetId", strconv.Itoa(*n.LocalSheetID)))
+				}
+				if n.Hidden {
+					attrs = append(attrs, attr("hidden", "1"))
+				}
+				out.textElement("definedName", n.Data, attrs...)
+			}
+			out.end("definedNames")
+		}
+		out.end("workbook")
+	})
+	if err != nil {
+		return err
+	}
+	err = this.writePart("xl/_rels/workbook.xml.rels", func(out *xmlWriter) {
+		out.start("Relationships", attr("xmlns", "http://schemas.openxmlformats.org/package/2006/relationships"))
+		for i := range this.sheets {
+			out.element("Relationship", attr("Id", "rId"+strconv.Itoa(i+1)), attr("Type", relsNamespace+"/worksheet"),
+				attr("Target", fmt.Sprintf("worksheets/sheet%d.xml", i+1)))
+		}
+		n := len(this.sheets)
+		out.element("Relationship", attr("Id", "rId"+strconv.Itoa(n+1)), attr("Type", relsNamespace+"/styles"), attr("Target", "styles.xml"))
+		if len(this.stringList) > 0 {
+			out.element("Relationship", attr("Id", "rId"+strconv.Itoa(n+2)), attr("Type", relsNamespace+"/sharedStrings"), attr("Target", "sharedStrings.xml"))
+		}
+		out.end("Relationships")
+	})
+	if err != nil {
+		return err
+	}
+	return this.writePart("_rels/.rels", func(out *xmlWriter) {
+		out.start("Relationships", attr("xmlns", "http://schemas.openxmlformats.org/package/2006/relationships"))
+		out.element("Relationship", attr("Id", "rId1"), attr("Type", relsNamespace+"/officeDocument"), attr("Target", "xl/workbook.xml"))
+		out.end("Relationships")
+	})
+}
+
+func (this *writer) writeContentTypes() error {
+	const prefix = "application/vnd.openxmlformats-officedocument.spreadsheetml."
+	return this.writePart("[Content_Types].xml", func(out *xmlWriter) {
+		out.start("Types", attr("xmlns", "http://schemas.openxmlformats.org/package/2006/content-types"))
+		out.element("Default", attr("Extension", "rels"), attr("ContentType", "application/vnd.openxmlformats-package.relationships+xml"))
+		out.element("Default", attr("Extension", "xml"), attr("ContentType", "application/xml"))
+		out.element("Override", attr("PartName", "/xl/workbook.xml"), attr("ContentType", prefix+"sheet.main+xml"))
+		for i := range this.sheets {
+			out.element("Override", attr("PartName", fmt.Sprintf("/xl/worksheets/sheet%d.xml", i+1)), attr("ContentType", prefix+"worksheet+xml"))
+		}
+		out.element("Override", attr("PartName", "/xl/styles.xml"), attr("ContentType", prefix+"styles+xml"))
+		if len(this.stringList) > 0 {
+			out.element("Override", attr("PartName", "/xl/sharedStrings.xml"), attr("ContentType", prefix+"sharedStrings+xml"))
+		}
+		out.end("Types")
+	})
+}
+
+//工作表名称包含空格等字符时需要加单引号，如'My Sheet'；
+//不以字母或下划线开头，或可以解析为单元格引用(A1、R1C1、R、C)的名称也需要加单引号，如'2024'、'A1'
+func quoteSheetName(name string) string {
+	quoted := "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	for i, r := range name {
+		if i == 0 && !(r == '_' || unicode.IsLetter(r)) {
+			return quoted
+		}
+		if !(r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127) {
+			return quoted
+		}
+	}
+	if _, err := cellref.ParseA1(name); err == nil {
+		return quoted
+	}
+	if _, err := cellref.ParseR1C1(name, cellref.Ref{Row: 1}); err == nil || strings.EqualFold(name, "R") || strings.EqualFold(name, "C") {
+		return quoted
+	}
+	return name
+}
@@ -0,0 +1,205 @@
+package xlsx_reader
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fcodetop/xlsx-reader/cellref"
+)
+
+func TestWriter(t *testing.T) {
+	for _, shared := range []bool{true, false} {
+		var buf bytes.Buffer
+		w := Writer(&buf)
+		w.SetSharedStrings(shared)
+		if err := w.WriteRow("a"); err != ErrNoSheet {
+			t.Errorf("WriteRow without sheet: %v", err)
+		}
+		if err := w.AddSheet("会员"); err != nil {
+			t.Fatal(err)
+		}
+		w.SetColWidths(12, 0, 20)
+		w.SetFreezeRows(1)
+		w.SetAutoFilter(true)
+		w.WriteStrings([]string{"昵称", "积分", "生日", "启用"})
+		w.WriteRow("张三", 12.5, time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC), true)
+		w.WriteRow(" 李四 ", int64(7), time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), false)
+		if err := w.SetFreezeRows(2); err != ErrSheetStarted {
+			t.Errorf("SetFreezeRows after rows: %v", err)
+		}
+		if err := w.AddSheet("会员"); err != ErrInvalidSheetName {
+			t.Errorf("duplicate sheet: %v", err)
+		}
+		if err := w.AddSheet("My Sheet"); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteRowAt(3, nil, "张三")
+		if err := w.SetSheetState("hide"); !errors.Is(err, ErrInvalid) {
+			t.Errorf("SetSheetState: %v", err)
+		}
+		w.SetSheetState("hidden")
+		w.DefineName("积分列", "'会员'!$B:$B", false)
+		if err := w.DefineName("积分列", "'会员'!$C:$C", false); !errors.Is(err, ErrInvalid) {
+			t.Errorf("duplicate name: %v", err)
+		}
+		if err := w.WriteRowAt(2, "x"); !errors.Is(err, ErrRowOrder) {
+			t.Errorf("WriteRowAt before last row: %v", err)
+		}
+		if err := w.WriteRowAt(1048577, "x"); !errors.Is(err, cellref.ErrRef) {
+			t.Errorf("WriteRowAt after the last sheet row: %v", err)
+		}
+		if err := w.WriteRow(make([]interface{}, 16385)...); err != ErrTooManyColumns {
+			t.Errorf("WriteRow with 16385 columns: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		files := unzipAll(t, buf.Bytes())
+		if _, ok := files["xl/sharedStrings.xml"]; ok != shared {
+			t.Errorf("shared=%v sharedStrings.xml=%v", shared, ok)
+		}
+		sheet := files["xl/worksheets/sheet1.xml"]
+		for _, s := range []string{`state="frozen"`, `<col min="3" max="3" width="20" customWidth="1"/>`, `<autoFilter ref="A1:D3"/>`} {
+			if !strings.Contains(sheet, s) {
+				t.Errorf("sheet1.xml missing %s", s)
+			}
+		}
+		if !strings.Contains(files["xl/workbook.xml"], `<definedName name="积分列">'会员'!$B:$B</definedName>`) {
+			t.Errorf("workbook.xml=%s", files["xl/workbook.xml"])
+		}
+		if !strings.Contains(files["xl/workbook.xml"], `>'会员'!$A$1:$D$3</definedName>`) && !strings.Contains(files["xl/workbook.xml"], `>会员!$A$1:$D$3</definedName>`) {
+			t.Errorf("workbook.xml=%s", files["xl/workbook.xml"])
+		}
+
+		wb, err := OpenWorkbookFromBytes(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sheets := wb.Sheets(); len(sheets) != 2 || sheets[1].Name != "My Sheet" || sheets[1].State != "hidden" {
+			t.Errorf("sheets=%v", sheets)
+		}
+		r, err := wb.SheetReader("会员", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cols, err := r.Open()
+		if err != nil || strings.Join(cols, ",") != "昵称,积分,生日,启用" {
+			t.Fatalf("cols=%q err=%v", cols, err)
+		}
+		var rows [][]Cell
+		for r.Next() {
+			rows = append(rows, r.Cells())
+		}
+		if err = r.Err(); err != nil || len(rows) != 2 {
+			t.Fatalf("rows=%d err=%v", len(rows), err)
+		}
+		if rows[0][0].Raw != "张三" || rows[1][0].Raw != " 李四 " {
+			t.Errorf("names=%q %q", rows[0][0].Raw, rows[1][0].Raw)
+		}
+		if f, _ := rows[0][1].Float(); f != 12.5 || rows[1][1].Raw != "7" {
+			t.Errorf("numbers=%v %q", rows[0][1].Value, rows[1][1].Raw)
+		}
+		for i, want := range []time.Time{time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)} {
+			if got, ok := rows[i][2].Time(); rows[i][2].Kind != KindDate || !ok || !got.Round(time.Millisecond).Equal(want) {
+				t.Errorf("row %d date=%v kind=%v", i, rows[i][2].Value, rows[i][2].Kind)
+			}
+		}
+		if b, _ := rows[0][3].Bool(); rows[0][3].Kind != KindBool || !b {
+			t.Errorf("bool=%v", rows[0][3].Value)
+		}
+		if r.FormatCell(rows[1][2]) != "2020-01-02 03:04:05" {
+			t.Errorf("format=%q", r.FormatCell(rows[1][2]))
+		}
+		r.Close()
+
+		r, _ = wb.SheetReader("My Sheet", false)
+		r.Open()
+		r.Next()
+		if row := r.Row(); r.RowNumber() != 3 || strings.Join(row, ",") != ",张三" {
+			t.Errorf("row %d=%q", r.RowNumber(), row)
+		}
+		r.Close()
+		wb.Close()
+	}
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Writer(&buf).Close(); err != nil {
+		t.Fatal(err)
+	}
+	wb, err := OpenWorkbookFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	if sheets := wb.Sheets(); len(sheets) != 1 || sheets[0].Name != "Sheet1" {
+		t.Errorf("sheets=%v", sheets)
+	}
+}
+
+func TestExcelSerial(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1904, 1, 1, 6, 0, 0, 0, time.UTC),
+		time.Date(2023, 7, 15, 18, 30, 0, 0, time.UTC),
+	} {
+		for _, date1904 := range []bool{false, true} {
+			if date1904 && tm.Year() < 1904 {
+				continue
+			}
+			if got := GetExcelTime(ExcelSerial(tm, date1904), date1904); !got.Round(time.Millisecond).Equal(tm) {
+				t.Errorf("%v date1904=%v: got %v", tm, date1904, got)
+			}
+		}
+	}
+	//Excel 中1900-01-01 为1，1900-02-29 为60
+	for date, want := range map[time.Time]float64{
+		time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC):   1,
+		time.Date(1900, 2, 28, 12, 0, 0, 0, time.UTC): 59.5,
+		time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC):   61,
+	} {
+		if v := ExcelSerial(date, false); v != want {
+			t.Errorf("%v=%v, want %v", date, v, want)
+		}
+	}
+}
+
+func TestQuoteSheetName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Sheet1": "Sheet1", "会员": "会员", "_data.v2": "_data.v2", "ABC": "ABC",
+		"My Sheet": "'My Sheet'", "O'Neil": "'O''Neil'", "2024": "'2024'", "1月": "'1月'", ".x": "'.x'",
+		"A1": "'A1'", "$B$2": "'$B$2'", "xfd10": "'xfd10'", "R1C1": "'R1C1'", "RC": "'RC'", "r2c": "'r2c'", "R": "'R'", "c": "'c'",
+	} {
+		if got := quoteSheetName(name); got != want {
+			t.Errorf("quoteSheetName(%q)=%q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWriter_IllegalChars(t *testing.T) {
+	for _, shared := range []bool{true, false} {
+		var buf bytes.Buffer
+		w := Writer(&buf)
+		w.SetSharedStrings(shared)
+		w.AddSheet("Sheet1")
+		w.WriteStrings([]string{"a\x01b", "c_x0041_d", "e\uffffz\x00", "正常"})
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		unzipAll(t, buf.Bytes()) //xml 格式正确
+		r := ReaderFromBytes(buf.Bytes(), "", false)
+		if _, err := r.Open(); err != nil {
+			t.Fatal(err)
+		}
+		rows := fetchAll(t, r)
+		r.Close()
+		want := []string{"a_x0001_b", "c_x005F_x0041_d", "e_xFFFF_z_x0000_", "正常"}
+		if len(rows) != 1 || strings.Join(rows[0], "|") != strings.Join(want, "|") {
+			t.Errorf("shared=%v rows=%q", shared, rows)
+		}
+	}
+}
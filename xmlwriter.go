@@ -0,0 +1,213 @@
+package xlsx_reader
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+//按原样输出xml.Decoder.RawToken 读取的标记，保留命名空间前缀，
+//encoding/xml 的Encoder 会改写命名空间，不适用于修改xlsx 中的xml
+type xmlWriter struct {
+	w    *bufio.Writer
+	open bool //开始标签尚未结束，下一个标记为对应的结束标签时输出为<a/>
+}
+
+func newXmlWriter(w io.Writer) *xmlWriter {
+	return &xmlWriter{w: bufio.NewWriter(w)}
+}
+
+//带前缀的名称，如r:id
+func qname(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+func (this *xmlWriter) closeStart() {
+	if this.open {
+		this.w.WriteByte('>')
+		this.open = false
+	}
+}
+
+func (this *xmlWriter) writeToken(tok xml.Token) {
+	switch t := tok.(type) {
+	case xml.StartElement:
+		this.start(qname(t.Name), t.Attr...)
+	case xml.EndElement:
+		this.end(qname(t.Name))
+	case xml.CharData:
+		this.text(string(t))
+	case xml.Comment:
+		this.closeStart()
+		this.w.WriteString("<!--")
+		this.w.Write(t)
+		this.w.WriteString("-->")
+	case xml.ProcInst:
+		this.closeStart()
+		this.w.WriteString("<?" + t.Target)
+		if len(t.Inst) > 0 {
+			this.w.WriteByte(' ')
+			this.w.Write(t.Inst)
+		}
+		this.w.WriteString("?>")
+	case xml.Directive:
+		this.closeStart()
+		this.w.WriteString("<!")
+		this.w.Write(t)
+		this.w.WriteByte('>')
+	}
+}
+
+//开始标签，属性名可以带前缀，如xml.Name{Space: "r", Local: "id"}
+func (this *xmlWriter) start(name string, attrs ...xml.Attr) {
+	this.closeStart()
+	this.w.WriteByte('<')
+	this.w.WriteString(name)
+	for _, a := range attrs {
+		this.w.WriteByte(' ')
+		this.w.WriteString(qname(a.Name))
+		this.w.WriteString(`="`)
+		this.w.WriteString(attrEscaper.Replace(a.Value))
+		this.w.WriteByte('"')
+	}
+	this.open = true
+}
+
+func (this *xmlWriter) end(name string) {
+	if this.open {
+		this.w.WriteString("/>")
+		this.open = false
+		return
+	}
+	this.w.WriteString("</" + name + ">")
+}
+
+func (this *xmlWriter) text(s string) {
+	this.closeStart()
+	textEscaper.WriteString(this.w, s)
+}
+
+//没有子元素的元素，如<c r="A1"/>
+func (this *xmlWriter) element(name string, attrs ...xml.Attr) {
+	this.start(name, attrs...)
+	this.end(name)
+}
+
+//只有文本的元素，如<t>abc</t>
+func (this *xmlWriter) textElement(name, s string, attrs ...xml.Attr) {
+	this.start(name, attrs...)
+	this.text(s)
+	this.end(name)
+}
+
+//已转义的xml 片段
+func (this *xmlWriter) raw(s string) {
+	this.closeStart()
+	this.w.WriteString(s)
+}
+
+func (this *xmlWriter) Flush() error {
+	this.closeStart()
+	return this.w.Flush()
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+//单元格、共享字符串及批注中的新文本：xml 1.0 不允许的控制字符及U+FFFE、U+FFFF 按Excel 的方式写为_xHHHH_，
+//原有的_xHHHH_ 形式的文本写为_x005F_xHHHH_，Excel 读取时还原；无效的UTF-8 字节替换为U+FFFD
+func escapeCellText(s string) string {
+	clean := true
+	for i, r := range s {
+		if !isXmlChar(r) || r == utf8.RuneError || (r == '_' && isHexEscape(s[i:])) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == utf8.RuneError:
+			sb.WriteRune(utf8.RuneError)
+		case !isXmlChar(r):
+			fmt.Fprintf(&sb, "_x%04X_", r)
+		case r == '_' && isHexEscape(s[i:]):
+			sb.WriteString("_x005F_")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+//xml 1.0 允许的字符
+func isXmlChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF)
+}
+
+//s 是否以_xHHHH_ 开头
+func isHexEscape(s string) bool {
+	if len(s) < 7 || s[1] != 'x' || s[6] != '_' {
+		return false
+	}
+	for i := 2; i < 6; i++ {
+		c := s[i] | 0x20
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func attr(name, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: value}
+}
+
+//元素的属性值
+func attrValue(attrs []xml.Attr, name string) (string, bool) {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+//设置属性值，没有该属性时追加，返回新的属性切片
+func setAttr(attrs []xml.Attr, name, value string) []xml.Attr {
+	out := make([]xml.Attr, 0, len(attrs)+1)
+	found := false
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			a.Value = value
+			found = true
+		}
+		out = append(out, a)
+	}
+	if !found {
+		out = append(out, attr(name, value))
+	}
+	return out
+}
+
+//去掉属性，返回新的属性切片
+func removeAttr(attrs []xml.Attr, name string) []xml.Attr {
+	out := make([]xml.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a.Name.Space != "" || a.Name.Local != name {
+			out = append(out, a)
+		}
+	}
+	return out
+}